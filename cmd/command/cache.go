@@ -0,0 +1,548 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iEchoxu/clinvarDL/configs"
+	"github.com/iEchoxu/clinvarDL/configs/defaults"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/cache"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/output"
+	_ "github.com/iEchoxu/clinvarDL/pkg/entrez/output/excel" // 注册 xlsx 格式的写入器
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/spf13/cobra"
+)
+
+const maxQueryWidth = 48 // list 命令中查询内容的最大显示宽度
+
+// cacheCmd 查看及管理缓存
+// Run: ./clinvarDL cache list/inspect/lookup/stats/purge/export
+var cacheCmd = &cobra.Command{
+	Use:       "cache",
+	Short:     "inspect and manage the query cache",
+	Long:      `inspect and manage the query cache configured in cache_setting, without touching the network`,
+	Args:      cobra.MatchAll(cobra.OnlyValidArgs),
+	ValidArgs: []string{"list", "inspect", "lookup", "stats", "purge", "export"},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := logcdl.InitLogger(logcdl.Options{
+			MinLevel:    logcdl.WARN,
+			LogDir:      "logs",
+			LogFileName: "clinvarDL_%s.log",
+			TimeFormat:  "2006-01-02",
+		}); err != nil {
+			fmt.Println("无法初始化日志:", err)
+			os.Exit(1)
+		}
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		logcdl.Close()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		if err != nil {
+			return
+		}
+	},
+}
+
+// listCacheCmd 列出所有缓存
+// Run: ./clinvarDL cache list
+var listCacheCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list cached queries",
+	Long:  `list cached queries with their status, progress, age and size`,
+	Args:  cobra.MaximumNArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		withInspector(func(_ *config.Config, inspector cache.Inspector) error {
+			infos, err := inspector.List()
+			if err != nil {
+				return err
+			}
+			if len(infos) == 0 {
+				fmt.Println("缓存为空")
+				return nil
+			}
+
+			// 最近创建的缓存在前
+			sort.Slice(infos, func(i, j int) bool {
+				return infos[i].CreatedAt.After(infos[j].CreatedAt)
+			})
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "QUERY ID\tQUERY\tSTATUS\tPROGRESS\tAGE\tSIZE")
+			for _, info := range infos {
+				status := string(info.Status)
+				if info.Expired {
+					status += " (expired)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					info.QueryID, truncate(info.Query, maxQueryWidth), status, info.Progress,
+					formatAge(time.Since(info.CreatedAt)), formatSize(info.Size))
+			}
+			return w.Flush()
+		})
+	},
+}
+
+// inspectCacheCmd 查看单个缓存的详细信息
+// Run: ./clinvarDL cache inspect <id>
+var inspectCacheCmd = &cobra.Command{
+	Use:   "inspect <id>",
+	Short: "show failed batches and errors of a cached query",
+	Long:  `show failed batches and errors of a cached query, <id> is the query ID shown by list or the cache key`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withInspector(func(_ *config.Config, inspector cache.Inspector) error {
+			infos, err := findEntries(inspector, args[0])
+			if err != nil {
+				return err
+			}
+
+			for i, info := range infos {
+				result, err := inspector.Load(info.Key)
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					fmt.Println()
+				}
+				printEntry(info, result)
+			}
+			return nil
+		})
+	},
+}
+
+// lookupCacheCmd 按 VariationID 在所有缓存的查询中查找变异, 需要使用 bolt 后端
+// Run: ./clinvarDL cache lookup <VariationID>
+var lookupCacheCmd = &cobra.Command{
+	Use:   "lookup <VariationID>",
+	Short: "find a variant in all cached queries by VariationID",
+	Long:  `find a variant in all cached queries by VariationID (e.g. 17661 or VCV000017661), requires cache_setting.backend: bolt`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withInspector(func(cfg *config.Config, inspector cache.Inspector) error {
+			index, ok := inspector.(cache.VariantIndex)
+			if !ok {
+				return fmt.Errorf("cache backend %s does not index variants, set cache_setting.backend to %s", cfg.Cache.Backend, config.CacheBackendBolt)
+			}
+
+			uid, err := parseVariationID(args[0])
+			if err != nil {
+				return err
+			}
+
+			entries, err := index.LookupVariant(uid)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Printf("No cached query contains VariationID %s\n", uid)
+				return nil
+			}
+
+			printVariant(entries[0].Summary)
+
+			fmt.Printf("\nFound in %d cached queries:\n", len(entries))
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "QUERY ID\tQUERY\tKEY")
+			for _, entry := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", entry.QueryID, truncate(entry.Query, maxQueryWidth), entry.Key)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+// statsCacheCmd 查看缓存统计信息
+// Run: ./clinvarDL cache stats
+var statsCacheCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "show cache totals and hit ratio of recent runs",
+	Long:  `show cache totals and hit ratio of recent runs`,
+	Args:  cobra.MaximumNArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		withInspector(func(cfg *config.Config, inspector cache.Inspector) error {
+			infos, err := inspector.List()
+			if err != nil {
+				return err
+			}
+
+			var size int64
+			var expired int
+			statuses := make(map[types.QueryStatus]int)
+			for _, info := range infos {
+				size += info.Size
+				statuses[info.Status]++
+				if info.Expired {
+					expired++
+				}
+			}
+
+			fmt.Printf("Cache:   %s (backend: %s)\n", cfg.Cache.Dir, cfg.Cache.Backend)
+			fmt.Printf("Entries: %d (success: %d, partial: %d, failed: %d, expired: %d)\n",
+				len(infos), statuses[types.QueryStatusSuccess], statuses[types.QueryStatusPartial], statuses[types.QueryStatusFailed], expired)
+			fmt.Printf("Size:    %s / %s\n", formatSize(size), formatSize(cfg.Cache.MaxSize))
+
+			runs, err := cache.LoadRuns(cfg.Cache.Dir)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				fmt.Println("\n暂无运行记录")
+				return nil
+			}
+
+			fmt.Println()
+			total := &cache.RunStats{}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "STARTED AT\tQUERIES\tHITS\tRESUMED\tMISSES\tHIT RATIO")
+			for i := len(runs) - 1; i >= 0; i-- {
+				run := runs[i]
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.1f%%\n",
+					run.StartedAt.Format("2006-01-02 15:04:05"), run.Queries, run.Hits, run.Resumed, run.Misses, run.HitRatio()*100)
+				total.Queries += run.Queries
+				total.Hits += run.Hits
+				total.Resumed += run.Resumed
+				total.Misses += run.Misses
+			}
+			fmt.Fprintf(w, "TOTAL (%d runs)\t%d\t%d\t%d\t%d\t%.1f%%\n",
+				len(runs), total.Queries, total.Hits, total.Resumed, total.Misses, total.HitRatio()*100)
+			return w.Flush()
+		})
+	},
+}
+
+var (
+	purgeExpired bool
+	purgeFailed  bool
+	purgeAll     bool
+)
+
+// purgeCacheCmd 删除缓存
+// Run: ./clinvarDL cache purge --expired/--failed/--all
+var purgeCacheCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "delete expired, failed or all cached queries",
+	Long:  `delete expired, failed (not fully downloaded) or all cached queries together with their cached batches`,
+	Args:  cobra.MaximumNArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		withInspector(func(_ *config.Config, inspector cache.Inspector) error {
+			if purgeAll {
+				if err := inspector.Clear(); err != nil {
+					return err
+				}
+				fmt.Println("已删除全部缓存")
+				return nil
+			}
+
+			infos, err := inspector.List()
+			if err != nil {
+				return err
+			}
+
+			var removed int
+			for _, info := range infos {
+				if (purgeExpired && info.Expired) || (purgeFailed && info.Status != types.QueryStatusSuccess) {
+					if err := inspector.Delete(info.Key); err != nil {
+						return err
+					}
+					removed++
+				}
+			}
+
+			// 同时清理未完成查询遗留的过期批次
+			if purgeExpired {
+				if c, ok := inspector.(cache.Cache); ok {
+					if err := c.CleanExpired(); err != nil {
+						return err
+					}
+				}
+			}
+
+			fmt.Printf("已删除 %d 条缓存\n", removed)
+			return nil
+		})
+	},
+}
+
+var (
+	exportOutput string
+	exportFormat string
+)
+
+// exportCacheCmd 将缓存的查询结果写入文件
+// 输出格式由 --format 指定, 未指定时根据 -o 的扩展名识别, 均未指定时为 xlsx
+// Run: ./clinvarDL cache export <id> -o result.xlsx
+var exportCacheCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "write a cached result to a file without touching the network",
+	Long:  `write a cached result to a file without touching the network, <id> is the query ID shown by list or the cache key`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withInspector(func(cfg *config.Config, inspector cache.Inspector) error {
+			batches, ok := inspector.(cache.BatchCache)
+			if !ok {
+				return fmt.Errorf("cache backend %s does not store batches", cfg.Cache.Backend)
+			}
+
+			format := exportFormat
+			if format == "" {
+				format = output.DetectFormat(exportOutput)
+			}
+			if format == "" {
+				format = output.FormatXLSX
+			}
+			format = strings.ToLower(strings.TrimPrefix(format, "."))
+
+			resultWriter, err := output.NewWriter(format, defaultSheetName)
+			if err != nil {
+				return err
+			}
+			defer resultWriter.Close()
+
+			infos, err := findEntries(inspector, args[0])
+			if err != nil {
+				return err
+			}
+
+			// 同一查询使用不同参数 (如不同的过滤条件) 的缓存不能混合导出, 需要指定缓存键
+			if len(infos) > 1 {
+				keys := make([]string, 0, len(infos))
+				for _, info := range infos {
+					keys = append(keys, info.Key)
+				}
+				return fmt.Errorf("'%s' matches %d cached queries with different parameters, export one of them by cache key: %s", args[0], len(infos), strings.Join(keys, ", "))
+			}
+
+			result, err := inspector.Load(infos[0].Key)
+			if err != nil {
+				return err
+			}
+			results := []*types.QueryResult{result}
+
+			outputPath := exportOutput
+			if outputPath == "" {
+				outputPath = cfg.Output.GetOutputPath(fmt.Sprintf("%s_%s.%s", infos[0].QueryID, time.Now().Format("2006-01-02_15-04-05"), format))
+			} else if !strings.EqualFold(filepath.Ext(outputPath), "."+format) {
+				outputPath += "." + format
+			}
+			if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+				return err
+			}
+
+			if err := entrez.ExportResults(context.Background(), results, batches, outputPath, resultWriter); err != nil {
+				return err
+			}
+
+			fmt.Println("结果已保存到:", outputPath)
+			return nil
+		})
+	},
+}
+
+// withInspector 按配置文件中的 cache_setting 打开缓存并执行 fn, 出错时打印错误并退出
+func withInspector(fn func(cfg *config.Config, inspector cache.Inspector) error) {
+	cfg, store, err := openCache()
+	if err != nil {
+		fmt.Println("无法打开缓存:", err)
+		os.Exit(1)
+	}
+
+	inspector, ok := store.(cache.Inspector)
+	if !ok {
+		store.Close()
+		fmt.Printf("缓存后端 %s 不支持查看及管理缓存\n", cfg.Cache.Backend)
+		os.Exit(1)
+	}
+
+	err = fn(cfg, inspector)
+	store.Close()
+	if err != nil {
+		fmt.Println("错误:", err)
+		os.Exit(1)
+	}
+}
+
+// openCache 按配置文件中的 cache_setting 打开缓存, 返回的配置包含缓存及输出目录
+func openCache() (*config.Config, cache.Cache, error) {
+	cf := configs.Config{
+		Configs: []configs.ConfigFile{
+			{Config: configs.NewEntrezSettingConfig(), FilePath: defaults.SettingsConfigPath()},
+		},
+	}
+
+	settings, err := cf.LoadSettings()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := config.NewConfig(settings.EntrezSetting.DB).
+		SetCacheEnabled(true).
+		SetCacheDir(settings.CacheSetting.Dir).
+		SetCacheTTL(settings.CacheSetting.TTL).
+		SetCacheMaxSize(settings.CacheSetting.MaxSize).
+		SetCacheMaxEntries(settings.CacheSetting.MaxEntries).
+		SetCacheBackend(config.CacheBackend(settings.CacheSetting.Backend)).
+		SetOutputDir(settings.OutputSetting.Storage)
+
+	store, err := cache.New(cfg.Cache)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, store, nil
+}
+
+// findEntries 按查询ID或缓存键查找缓存, 同一查询使用不同参数时可能有多条缓存
+func findEntries(inspector cache.Inspector, id string) ([]*cache.EntryInfo, error) {
+	infos, err := inspector.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*cache.EntryInfo
+	for _, info := range infos {
+		if info.Key == id || info.QueryID == id {
+			matched = append(matched, info)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no cached query found for '%s'", id)
+	}
+
+	return matched, nil
+}
+
+// printEntry 打印单条缓存的详细信息
+func printEntry(info *cache.EntryInfo, result *types.QueryResult) {
+	fmt.Printf("Key:            %s\n", info.Key)
+	fmt.Printf("Query ID:       %s\n", result.QueryID)
+	fmt.Printf("Query:          %s\n", result.Query)
+	fmt.Printf("Status:         %s (%s)\n", result.Status, result.Progress)
+	fmt.Printf("Records:        %d/%d\n", result.ProcessedCount, result.TotalRecords)
+	fmt.Printf("Batches:        %d (failed: %d)\n", result.TotalBatches, len(result.FailedBatches))
+	if result.SplitQueries > 0 {
+		fmt.Printf("Split queries:  %d\n", result.SplitQueries)
+	}
+	fmt.Printf("Created at:     %s (expired: %t)\n", result.CreatedAt.Format("2006-01-02 15:04:05"), info.Expired)
+	fmt.Printf("Duration:       %s\n", result.Duration)
+	if result.Fingerprint != "" {
+		fmt.Printf("Fingerprint:    %s\n", result.Fingerprint)
+	}
+	if result.Error != nil {
+		fmt.Printf("Error:          %v\n", result.Error)
+	}
+
+	if len(result.FailedBatches) > 0 {
+		fmt.Println("Failed batches:")
+		for _, batch := range result.FailedBatches {
+			fmt.Printf("  #%d start=%d size=%d: %s\n", batch.BatchNum, batch.Start, batch.Size, batch.ErrMsg)
+		}
+	}
+
+	if len(result.MissingUIDs) > 0 {
+		fmt.Printf("Missing UIDs (%d): %s\n", len(result.MissingUIDs), strings.Join(result.MissingUIDs, ", "))
+	}
+}
+
+// parseVariationID 解析 VariationID, 同时接受 VCV 号 (如 VCV000017661.122)
+func parseVariationID(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if len(id) > 3 && strings.EqualFold(id[:3], "VCV") {
+		id, _, _ = strings.Cut(id[3:], ".")
+	}
+
+	uid, err := strconv.Atoi(id)
+	if err != nil || uid <= 0 {
+		return "", fmt.Errorf("invalid VariationID '%s', expected format: 17661 or VCV000017661", id)
+	}
+
+	return strconv.Itoa(uid), nil
+}
+
+// printVariant 打印变异的文档摘要
+func printVariant(doc *types.DocumentSummary) {
+	genes := make([]string, 0, len(doc.Genes.Gene))
+	for _, gene := range doc.Genes.Gene {
+		genes = append(genes, gene.Symbol)
+	}
+
+	fmt.Printf("VariationID:    %s\n", doc.Uid)
+	fmt.Printf("Accession:      %s\n", doc.AccessionVersion)
+	fmt.Printf("Title:          %s\n", doc.Title)
+	fmt.Printf("Genes:          %s\n", strings.Join(genes, ", "))
+	fmt.Printf("Type:           %s\n", doc.VariationSet.Variation.VariantType)
+	for _, assembly := range doc.VariationSet.Variation.VariationLoc.AssemblySet {
+		fmt.Printf("Location:       %s chr%s:%s-%s\n", assembly.AssemblyName, assembly.Chr, assembly.Start, assembly.Stop)
+	}
+	if classification := doc.GermlineClassification; classification.Description != "" {
+		fmt.Printf("Germline:       %s (%s)\n", classification.Description, classification.ReviewStatus)
+	}
+}
+
+// truncate 截断过长的字符串
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// formatAge 将时长格式化为易读的形式, 如: 5m、3h12m、2d4h
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// formatSize 将字节数格式化为易读的形式
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func init() {
+	purgeCacheCmd.Flags().BoolVar(&purgeExpired, "expired", false, "delete expired and outdated cached queries")
+	purgeCacheCmd.Flags().BoolVar(&purgeFailed, "failed", false, "delete cached queries that were not fully downloaded")
+	purgeCacheCmd.Flags().BoolVar(&purgeAll, "all", false, "delete all cached queries and batches")
+	purgeCacheCmd.MarkFlagsOneRequired("expired", "failed", "all")
+	purgeCacheCmd.MarkFlagsMutuallyExclusive("expired", "failed", "all")
+
+	exportCacheCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "output file (default: <query id>_<time>.<format> in the output directory)")
+	exportCacheCmd.Flags().StringVarP(&exportFormat, "format", "f", "", fmt.Sprintf("output format, one of: %s (default: detected from the -o extension, otherwise xlsx)", strings.Join(output.SupportedFormats(), ", ")))
+
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(listCacheCmd)
+	cacheCmd.AddCommand(inspectCacheCmd)
+	cacheCmd.AddCommand(lookupCacheCmd)
+	cacheCmd.AddCommand(statsCacheCmd)
+	cacheCmd.AddCommand(purgeCacheCmd)
+	cacheCmd.AddCommand(exportCacheCmd)
+}
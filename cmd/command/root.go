@@ -12,7 +12,7 @@ var rootCmd = &cobra.Command{
 	Short:     "Download clinvar data",
 	Long:      `Download clinvar data and generate excel`,
 	Args:      cobra.MatchAll(cobra.OnlyValidArgs, cobra.MinimumNArgs(1)),
-	ValidArgs: []string{"configs", "filters", "run"},
+	ValidArgs: []string{"configs", "filters", "run", "cache"},
 	Run: func(cmd *cobra.Command, args []string) {
 
 	},
@@ -1,32 +1,32 @@
 package command
 
 import (
-	"context"
 	"fmt"
 	"github.com/iEchoxu/clinvarDL/configs"
 	"github.com/iEchoxu/clinvarDL/configs/defaults"
+	"github.com/iEchoxu/clinvarDL/configs/settings"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/input"
-	"github.com/iEchoxu/clinvarDL/pkg/entrez/output/excel"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
 	"github.com/iEchoxu/clinvarDL/pkg/platform/path"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 )
 
-// 启动命令 clinvarDL run -f gene.txt
-// 目前只支持使用 gene symbol 查询
+// 启动命令 clinvarDL run -f gene.txt、clinvarDL run -t tasks.yaml、clinvarDL run -q BRCA1 -q TP53
+// 或 cat gene.txt | clinvarDL run -
+// 查询类型由配置文件中的 search_type 决定, 多任务文件中可为每个任务单独设置
 
 var runCmd = &cobra.Command{
-	Use:   "run",
+	Use:   "run [-]",
 	Short: "Start ClinvarDL",
 	Long:  `Start clinvarDL and run tasks`,
-	Args:  cobra.MaximumNArgs(0),
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		start := time.Now()
 
@@ -43,12 +43,33 @@ var runCmd = &cobra.Command{
 		}
 		defer logcdl.Close()
 
-		searchFile, _ = path.NormalizePath(searchFile)
+		defer func() {
+			logcdl.Tip("total time taken: %s", time.Since(start))
+		}()
 
-		// 校验输入文件是否合规范
-		if err := validateArgs(searchFile); err != nil {
-			logcdl.Error("Error in query file path or format: %v", err)
-			return
+		// clinvarDL run - 从标准输入读取查询
+		if len(args) > 0 {
+			if args[0] != input.Stdin || searchFile != "" || tasksFile != "" || len(inlineQueries) > 0 {
+				logcdl.Error("unexpected argument '%s', use '-' alone to read queries from stdin", args[0])
+				return
+			}
+			searchFile = input.Stdin
+		}
+
+		// 未指定多任务文件时, 将 -f 指定的任务文件、标准输入或 --query 指定的检索词作为单个任务运行
+		var tasks []*configs.TaskConfig
+		if tasksFile != "" {
+			tasksFile, _ = path.NormalizePath(tasksFile)
+			tasksConfig, err := configs.LoadTasks(tasksFile)
+			if err != nil {
+				logcdl.Error("failed to load tasks file '%s': %v", tasksFile, err)
+				return
+			}
+			tasks = tasksConfig.Tasks
+		} else if len(inlineQueries) > 0 {
+			tasks = []*configs.TaskConfig{{Name: defaultTaskName, Queries: inlineQueries}}
+		} else {
+			tasks = []*configs.TaskConfig{{Name: defaultTaskName, File: searchFile, Format: inputFormat}}
 		}
 
 		cf := configs.Config{
@@ -63,25 +84,36 @@ var runCmd = &cobra.Command{
 			return
 		}
 
+		// 命令行参数优先于配置文件中的日期范围及排序方式
+		overrideSearchSettings(cmd, settings.EntrezSetting)
+
 		// 创建配置
 		entrezConfig := config.NewConfig(settings.EntrezSetting.DB)
 
-		// 设置配置
-		entrezConfig.SetFilters(configs.NewFiltersConfigWithPath(defaults.FiltersConfigPath()).BuildQueryStringWithTerm()).
-			SetRetMax(settings.EntrezSetting.RetMax).
+		// 设置配置, 过滤条件由各任务单独设置
+		entrezConfig.SetRetMax(settings.EntrezSetting.RetMax).
 			SetUseHistory(settings.EntrezSetting.UseHistory).
 			SetRetMode(config.RetMode(settings.EntrezSetting.RetMode)).
 			SetApiKey(settings.EntrezSetting.ApiKey).
 			SetEmail(settings.EntrezSetting.Email).
 			SetToolName(settings.EntrezSetting.ToolName).
+			SetDateRange(settings.EntrezSetting.DateType, settings.EntrezSetting.MinDate, settings.EntrezSetting.MaxDate).
+			SetRelDate(settings.EntrezSetting.RelDate).
+			SetSort(settings.EntrezSetting.Sort).
 			SetCacheEnabled(settings.CacheSetting.Enabled).
 			SetCacheDir(settings.CacheSetting.Dir).
 			SetCacheTTL(settings.CacheSetting.TTL).
 			SetCacheMaxSize(settings.CacheSetting.MaxSize).
+			SetCacheMaxEntries(settings.CacheSetting.MaxEntries).
+			SetCacheBackend(config.CacheBackend(settings.CacheSetting.Backend)).
 			SetOutputDir(settings.OutputSetting.Storage). // 设置输出目录
+			SetMaxQueryRecords(settings.EntrezSetting.MaxQueryRecords).
+			SetReconcile(settings.EntrezSetting.Reconcile).
 			SetQueryTimeout(settings.TimeoutSetting.QueryTimeout).
 			SetSingleQueryTimeout(settings.TimeoutSetting.SingleQueryTimeout).
 			SetWriteTimeout(settings.TimeoutSetting.WriteTimeout).
+			SetFetchVCV(settings.EnrichmentSetting.FetchVCV).
+			SetLinks(settings.EnrichmentSetting.Links).
 			SetStreamEnabled(true) // 启用流式处理
 
 		// 统一验证配置
@@ -90,69 +122,119 @@ var runCmd = &cobra.Command{
 			return
 		}
 
-		// 读取并解析输入文件
-		parser := input.NewFileParser(settings.EntrezSetting.BatchSize, settings.GetSearchFlag(settings.EntrezSetting.SearchType))
-		queries, err := parser.ParseFile(searchFile)
-		if err != nil {
-			logcdl.Error("failed to parse input file '%s': %v", searchFile, err)
-			return
-		}
+		parserOptions := []input.FileParserOption{input.WithAssembly(settings.EntrezSetting.Assembly)}
 
-		// 设置上下文和超时
-		ctx, cancel := context.WithTimeout(context.Background(), entrezConfig.Runtime.QueryTimeout)
-		defer cancel()
+		// 命令行参数优先于配置文件中的 hgnc_file
+		if hgncFile == "" {
+			hgncFile = settings.EntrezSetting.HGNCFile
+		}
+		if hgncFile != "" {
+			hgncFile, _ = path.NormalizePath(hgncFile)
+			resolver, err := input.NewSymbolResolver(hgncFile)
+			if err != nil {
+				logcdl.Error("failed to load hgnc file '%s': %v", hgncFile, err)
+				return
+			}
+			parserOptions = append(parserOptions, input.WithResolver(resolver))
+			logcdl.Info("gene symbols will be resolved with hgnc file '%s'", hgncFile)
+		}
 
-		// 执行查询
 		service := entrez.NewEntrezService(entrezConfig)
-		results, err := service.ExecuteQueries(ctx, queries)
-
-		// 如果所有查询都失败了，退出程序
-		if err != nil {
-			logcdl.Error("%v", err)
-			return
-		}
+		defer service.Close()
 
-		// 创建 ExcelWriter
-		resultWriter, err := excel.NewWriter("ClinVar Results")
-		if err != nil {
-			logcdl.Error("failed to create excel writer: %v", err)
+		runner := &taskRunner{
+			settings:      settings,
+			service:       service,
+			filters:       configs.NewFiltersConfigWithPath(defaults.FiltersConfigPath()),
+			parserOptions: parserOptions,
+			timestamp:     time.Now().Format("2006-01-02_15-04-05"),
+			multiTask:     tasksFile != "",
 		}
-		defer resultWriter.Close()
-
-		defer func() {
-			logcdl.Tip("total time taken: %s", time.Since(start))
-		}()
 
-		// 获取完整输出路径
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		outputFile := fmt.Sprintf("clinvar_results_%s.xlsx", timestamp)
-		outputPath := entrezConfig.Output.GetOutputPath(outputFile)
+		// 依次执行所有任务, 单个任务失败不影响其他任务
+		var failedTasks []string
+		for _, task := range tasks {
+			if runner.multiTask {
+				logcdl.Tip("running task '%s'", task.Name)
+			}
 
-		// 处理结果
-		err = service.ProcessResults(ctx, results, outputPath, resultWriter)
-		if err != nil {
-			logcdl.Error("failed to process results: %v", err)
-			return
+			if err := runner.run(task); err != nil {
+				logcdl.Error("task '%s' failed: %v", task.Name, err)
+				failedTasks = append(failedTasks, task.Name)
+			}
 		}
 
-		logcdl.Success("results have been saved to %s", outputPath)
+		if runner.multiTask {
+			if len(failedTasks) > 0 {
+				logcdl.Warn("%d of %d tasks failed: %s", len(failedTasks), len(tasks), strings.Join(failedTasks, ", "))
+				return
+			}
+			logcdl.Success("all %d tasks completed", len(tasks))
+		}
 	},
 }
 
-var searchFile string
+var (
+	searchFile    string
+	tasksFile     string
+	inlineQueries []string
+	inputFormat   string
+	tableOptions  input.TableOptions
+	hgncFile      string
+	dateType      string
+	minDate       string
+	maxDate       string
+	relDate       int
+	sortOrder     string
+)
 
 func init() {
 	runCmd.Flags().StringVarP(&searchFile, "file", "f", "", "./clinvarDL run  -f gene.txt")
+	runCmd.Flags().StringVarP(&tasksFile, "tasks", "t", "", "./clinvarDL run -t tasks.yaml, run all tasks in a yaml task file")
+	runCmd.Flags().StringArrayVarP(&inlineQueries, "query", "q", nil, "./clinvarDL run -q BRCA1 -q TP53, query inline terms (repeatable)")
+	runCmd.MarkFlagsMutuallyExclusive("file", "tasks", "query")
+	runCmd.Flags().StringVar(&inputFormat, "input-format", "", "input file format: "+strings.Join(input.SupportedFormats(), ", ")+" (default: detected by file extension)")
+	runCmd.Flags().StringVar(&tableOptions.Column, "column", "1", "column name or 1-based column number of the queries in csv/tsv/xlsx files")
+	runCmd.Flags().StringVar(&tableOptions.Sheet, "sheet", "", "sheet name of xlsx files (default: first sheet)")
+	runCmd.Flags().BoolVar(&tableOptions.Header, "header", false, "treat the first row of csv/tsv/xlsx files as a header")
+	runCmd.Flags().StringVar(&hgncFile, "hgnc", "", "local hgnc_complete_set.txt used to resolve gene aliases and previous symbols (overrides hgnc_file in settings)")
+	runCmd.Flags().StringVar(&dateType, "datetype", "", "date type of the date range: modification (mdat), publication (pdat), entrez (edat) (default: mdat)")
+	runCmd.Flags().StringVar(&minDate, "mindate", "", "only query records on or after this date, YYYY, YYYY/MM or YYYY/MM/DD (maxdate defaults to today)")
+	runCmd.Flags().StringVar(&maxDate, "maxdate", "", "only query records on or before this date, requires --mindate")
+	runCmd.Flags().IntVar(&relDate, "reldate", 0, "only query records within the last n days")
+	runCmd.Flags().StringVar(&sortOrder, "sort", "", "sort order of esearch results")
+	runCmd.MarkFlagsMutuallyExclusive("reldate", "mindate")
+	runCmd.MarkFlagsMutuallyExclusive("reldate", "maxdate")
 	rootCmd.AddCommand(runCmd)
 }
 
-func validateArgs(arg string) error {
+// overrideSearchSettings 使用命令行参数覆盖配置文件中的日期范围及排序方式
+// 指定了任一日期参数时忽略配置文件中的全部日期设置, 避免 reldate 与 mindate 混用
+func overrideSearchSettings(cmd *cobra.Command, es *settings.EntrezSettings) {
+	flags := cmd.Flags()
+	if flags.Changed("mindate") || flags.Changed("maxdate") || flags.Changed("reldate") {
+		es.MinDate, es.MaxDate, es.RelDate = minDate, maxDate, relDate
+	}
+	if flags.Changed("datetype") {
+		es.DateType = dateType
+	}
+	if flags.Changed("sort") {
+		es.Sort = sortOrder
+	}
+}
+
+func validateArgs(arg, format string) error {
 	if arg == "" {
-		return fmt.Errorf("参数为空: 请使用 -f 指定搜索文件路径")
+		return fmt.Errorf("参数为空: 请使用 -f 指定搜索文件路径, 或使用 - 从标准输入读取、--query 指定检索词")
+	}
+
+	if !slices.Contains(input.SupportedFormats(), strings.ToLower(format)) {
+		return fmt.Errorf("参数错误: 不支持的文件格式 %s, 支持的格式: %s", format, strings.Join(input.SupportedFormats(), ", "))
 	}
 
-	if filepath.Ext(arg) != ".txt" {
-		return fmt.Errorf("参数错误: 搜索文件必须为 txt 格式")
+	// 标准输入无需检查文件路径
+	if arg == input.Stdin {
+		return nil
 	}
 
 	if os.PathSeparator == '\\' && strings.Contains(arg, "/") {
@@ -169,3 +251,24 @@ func validateArgs(arg string) error {
 
 	return nil
 }
+
+// saveValidationReport 打印并保存输入文件的校验报告
+func saveValidationReport(report *input.ValidationReport, reportPath string) {
+	logcdl.Info("input validation: %d accepted, %d duplicate, %d rejected",
+		report.Count(input.StatusAccepted), report.Count(input.StatusDuplicate), report.Count(input.StatusRejected))
+
+	for _, entry := range report.Rejected() {
+		logcdl.Warn("rejected line %d '%s': %s", entry.Line, entry.Input, entry.Reason)
+	}
+
+	for _, entry := range report.Notes() {
+		logcdl.Warn("line %d '%s': %s", entry.Line, entry.Input, entry.Reason)
+	}
+
+	if err := report.Save(reportPath); err != nil {
+		logcdl.Warn("failed to save validation report: %v", err)
+		return
+	}
+
+	logcdl.Info("validation report has been saved to %s", reportPath)
+}
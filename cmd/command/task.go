@@ -0,0 +1,151 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"github.com/iEchoxu/clinvarDL/configs"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/input"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/output/excel"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"github.com/iEchoxu/clinvarDL/pkg/platform/path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTaskName  = "clinvar_results" // 单任务运行时的任务名, 同时作为输出文件名前缀
+	defaultSheetName = "ClinVar Results"
+)
+
+// taskRunner 保存同一次运行中所有任务共享的状态
+// 所有任务共享同一个 EntrezService 的 HTTP 客户端、限流器及缓存
+type taskRunner struct {
+	settings      *configs.EntrezSettingConfig
+	service       *entrez.EntrezService
+	filters       *configs.FiltersConfig   // 全局过滤条件，任务中的 filters 在此基础上覆盖
+	parserOptions []input.FileParserOption // 所有任务共用的解析器选项
+	timestamp     string                   // 输出文件统一使用本次运行的时间戳
+	multiTask     bool                     // 是否为多任务运行，多任务时输出文件名包含任务名
+}
+
+// run 执行单个任务: 解析输入、查询并保存结果
+func (r *taskRunner) run(task *configs.TaskConfig) error {
+	// 获取查询类型对应的检索字段标志
+	searchType := task.SearchType
+	if searchType == "" {
+		searchType = r.settings.EntrezSetting.SearchType
+	}
+	searchFlag := r.settings.GetSearchFlag(searchType)
+	if searchFlag == "" {
+		return fmt.Errorf("unsupported search type '%s', use one of: gene symbol, rsid, hgvs, variation id, accession, region, mixed", searchType)
+	}
+
+	// 在全局过滤条件的基础上应用任务中的覆盖项
+	filters, err := task.BuildFilters(r.filters)
+	if err != nil {
+		return err
+	}
+	taskConfig := r.service.Config.Clone().SetFilters(filters)
+
+	queries, report, err := r.parseTask(task, searchFlag)
+	if err != nil {
+		return err
+	}
+
+	// 在发起网络请求前保存输入校验报告
+	if report != nil {
+		reportFile := fmt.Sprintf("validation_report_%s.tsv", r.timestamp)
+		if r.multiTask {
+			reportFile = fmt.Sprintf("validation_report_%s_%s.tsv", task.FileName(), r.timestamp)
+		}
+		saveValidationReport(report, taskConfig.Output.GetOutputPath(reportFile))
+	}
+
+	if len(queries) == 0 {
+		return fmt.Errorf("no valid queries found, please check the validation report")
+	}
+
+	// 设置上下文和超时
+	ctx, cancel := context.WithTimeout(context.Background(), taskConfig.Runtime.QueryTimeout)
+	defer cancel()
+
+	sheet := task.Sheet
+	if sheet == "" {
+		sheet = defaultSheetName
+	}
+
+	// 创建 ExcelWriter
+	resultWriter, err := excel.NewWriter(sheet)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create excel writer")
+	}
+	defer resultWriter.Close()
+
+	// 获取完整输出路径
+	outputPath := taskConfig.Output.GetOutputPath(task.OutputFile(r.timestamp))
+
+	// 执行查询, 结果在查询过程中按批次写入
+	service := r.service.WithConfig(taskConfig)
+	results, err := service.ExecuteQueries(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	// 处理结果
+	if err := service.ProcessResults(ctx, results, outputPath, resultWriter); err != nil {
+		return errors.Wrapf(err, "failed to process results")
+	}
+
+	logcdl.Success("results have been saved to %s", outputPath)
+
+	return nil
+}
+
+// parseTask 解析任务中的检索词列表或任务文件
+func (r *taskRunner) parseTask(task *configs.TaskConfig, searchFlag string) ([]*types.Query, *input.ValidationReport, error) {
+	fileParser := input.NewFileParser(r.settings.EntrezSetting.BatchSize, searchFlag, r.parserOptions...)
+
+	// 检索词列表按行解析, 与 txt 任务文件的处理方式相同
+	if len(task.Queries) > 0 {
+		queries, err := fileParser.Parse(strings.NewReader(strings.Join(task.Queries, "\n")))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse queries")
+		}
+		return queries, fileParser.Report(), nil
+	}
+
+	file, _ := path.NormalizePath(task.File)
+
+	// 未指定输入格式时根据文件扩展名识别
+	format := task.Format
+	if format == "" {
+		format = input.DetectFormat(file)
+	}
+
+	// 校验输入文件是否合规范
+	if err := validateArgs(file, format); err != nil {
+		return nil, nil, errors.Wrapf(err, "error in query file path or format")
+	}
+
+	// 根据输入格式创建解析器
+	parser, err := input.NewParser(format, fileParser, task.TableOptions(tableOptions))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to create parser for '%s'", file)
+	}
+
+	// 读取并解析输入文件
+	queries, err := input.ParseFile(parser, file)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse input file '%s'", file)
+	}
+
+	var report *input.ValidationReport
+	if reporter, ok := parser.(input.Reporter); ok {
+		report = reporter.Report()
+	}
+
+	return queries, report, nil
+}
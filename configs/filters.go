@@ -55,6 +55,20 @@ func (fc *FiltersConfig) Read(configFile string) (Configer, error) {
 	return read(configFile, fc)
 }
 
+// load 读取 Path 指定的过滤条件配置文件, 返回新的 FiltersConfig, Path 为空时返回默认配置
+func (fc *FiltersConfig) load() (*FiltersConfig, error) {
+	if fc.Path == "" {
+		return NewFiltersConfig(), nil
+	}
+
+	configer, err := NewFiltersConfigWithPath(fc.Path).Read(fc.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return configer.(*FiltersConfig), nil
+}
+
 // getActivatedFilter 获得 filters 配置文件中值为 true 的属性
 func (fc *FiltersConfig) getActivatedFilter(configFile string) map[string][]string {
 	if configFile == "" {
@@ -68,11 +82,16 @@ func (fc *FiltersConfig) getActivatedFilter(configFile string) map[string][]stri
 		return nil
 	}
 
+	return configer.(*FiltersConfig).activatedFilters()
+}
+
+// activatedFilters 获得当前配置中值为 true 的属性
+func (fc *FiltersConfig) activatedFilters() map[string][]string {
 	activatedFilters := make(map[string][]string, 30) // 如果 filters 数据增多可适当增加此数值
 
 	// 只有结构体才能使用反射，指针类型的需要解引用
-	t := reflect.TypeOf(*configer.(*FiltersConfig))
-	v := reflect.ValueOf(*configer.(*FiltersConfig))
+	t := reflect.TypeOf(*fc)
+	v := reflect.ValueOf(*fc)
 
 	for i := 0; i < t.NumField(); i++ {
 		fieldValue := v.Field(i)
@@ -94,3 +113,8 @@ func (fc *FiltersConfig) getActivatedFilter(configFile string) map[string][]stri
 func (fc *FiltersConfig) BuildQueryStringWithTerm() string {
 	return filters.BuildQueryStringWithTerm(fc.getActivatedFilter(fc.Path))
 }
+
+// BuildQueryString 使用当前配置 (而非配置文件) 构建 Term 查询参数里的过滤条件
+func (fc *FiltersConfig) BuildQueryString() string {
+	return filters.BuildQueryStringWithTerm(fc.activatedFilters())
+}
@@ -2,13 +2,15 @@ package configs
 
 import (
 	"github.com/iEchoxu/clinvarDL/configs/settings"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
 )
 
 type EntrezSettingConfig struct {
-	EntrezSetting  *settings.EntrezSettings  `yaml:"entrez_setting"`
-	OutputSetting  *settings.OutputSettings  `yaml:"output_setting"`
-	CacheSetting   *settings.CacheSettings   `yaml:"cache_setting"`
-	TimeoutSetting *settings.TimeoutSettings `yaml:"timeout_setting"`
+	EntrezSetting     *settings.EntrezSettings     `yaml:"entrez_setting"`
+	OutputSetting     *settings.OutputSettings     `yaml:"output_setting"`
+	CacheSetting      *settings.CacheSettings      `yaml:"cache_setting"`
+	TimeoutSetting    *settings.TimeoutSettings    `yaml:"timeout_setting"`
+	EnrichmentSetting *settings.EnrichmentSettings `yaml:"enrichment_setting"`
 }
 
 type EntrezSettingConfigOption func(option *EntrezSettingConfig)
@@ -27,9 +29,10 @@ func WithInputProcessor(use bool) EntrezSettingConfigOption {
 // NewEntrezSettingConfig 创建并返回一个EntrezSettingConfig配置
 func NewEntrezSettingConfig(options ...EntrezSettingConfigOption) *EntrezSettingConfig {
 	cfg := &EntrezSettingConfig{
-		OutputSetting:  settings.NewOutputSettings(),
-		CacheSetting:   settings.NewCacheSettings(),
-		TimeoutSetting: settings.NewTimeoutSettings(),
+		OutputSetting:     settings.NewOutputSettings(),
+		CacheSetting:      settings.NewCacheSettings(),
+		TimeoutSetting:    settings.NewTimeoutSettings(),
+		EnrichmentSetting: settings.NewEnrichmentSettings(),
 	}
 
 	// 应用默认配置，确保EntrezSetting总是被初始化
@@ -54,16 +57,23 @@ func (esc *EntrezSettingConfig) Read(configFile string) (Configer, error) {
 
 /*
 searchType 定义查询类型
-目前只实现了 gene symbol 类型
+region 类型的查询内容为 chr17:43044295-43125483 格式的基因组区间
+mixed 类型会按行识别 rs 号、VCV/RCV 号、VariationID、HGVS、基因组区间及基因名
 详细：https://www.ncbi.nlm.nih.gov/clinvar/docs/help/
 */
 func (esc *EntrezSettingConfig) searchType() map[string]string {
 	return map[string]string{
-		"gene symbol": "[gene]",
+		"gene symbol":  types.FlagGene,
+		"rsid":         types.FlagRsID,
+		"hgvs":         types.FlagHGVS,
+		"variation id": types.FlagVariationID,
+		"accession":    types.FlagAccession,
+		"region":       types.FlagRegion,
+		"mixed":        types.FlagMixed,
 	}
 }
 
-// GetSearchFlag 根据查询类型返回对应的 clinvar 查询标志
+// GetSearchFlag 根据查询类型返回对应的 clinvar 查询标志，不支持的类型返回空字符串
 func (esc *EntrezSettingConfig) GetSearchFlag(searchType string) string {
 	return esc.searchType()[searchType]
 }
@@ -0,0 +1,16 @@
+package settings
+
+// EnrichmentSettings 定义 esummary 之后的可选补充阶段配置
+// 补充阶段会显著增加请求数量, 默认关闭
+type EnrichmentSettings struct {
+	FetchVCV bool     `yaml:"fetch_vcv"` // 是否通过 efetch 获取完整 VCV 记录 (提交者、判定标准、文献、所有转录本上的 HGVS)
+	Links    []string `yaml:"links"`     // 通过 elink 获取关联记录的目标数据库, 可选: pubmed、medgen、gene
+}
+
+// NewEnrichmentSettings 创建默认的补充阶段配置
+func NewEnrichmentSettings() *EnrichmentSettings {
+	return &EnrichmentSettings{
+		FetchVCV: false,
+		Links:    []string{},
+	}
+}
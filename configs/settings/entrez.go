@@ -18,11 +18,22 @@ type EntrezSettings struct {
 	RetMax     int    `yaml:"ret_max" `
 	RetMode    string `yaml:"ret_mode" `
 	UseHistory bool   `yaml:"use_history" `
-	SearchType string `yaml:"search_type"` // https://www.ncbi.nlm.nih.gov/clinvar/docs/help/
+	SearchType string `yaml:"search_type"` // gene symbol、rsid、hgvs、variation id、accession、region、mixed
+	Assembly   string `yaml:"assembly"`    // 区间检索使用的参考基因组版本: GRCh37 或 GRCh38
+	HGNCFile   string `yaml:"hgnc_file"`   // 本地 hgnc_complete_set.txt 路径, 设置后将基因别名及曾用名解析为批准的基因名
 	Email      string `yaml:"email"`
 	ToolName   string `yaml:"tool_name"`
 	ApiKey     string `yaml:"api_key"`
 	BatchSize  int    `yaml:"batch_size"`
+	DateType   string `yaml:"date_type"` // esearch 日期类型: modification (mdat)、publication (pdat)、entrez (edat)
+	MinDate    string `yaml:"min_date"`  // 起始日期, 格式: YYYY、YYYY/MM 或 YYYY/MM/DD, 只设置起始日期时结束日期为当天
+	MaxDate    string `yaml:"max_date"`  // 结束日期
+	RelDate    int    `yaml:"rel_date"`  // 只检索最近 n 天内的记录, 与 min_date/max_date 互斥
+	Sort       string `yaml:"sort"`      // esearch 结果的排序方式
+	// 合并了多个检索词的查询结果超过该值时拆分为子查询分别下载, 0 表示不拆分
+	MaxQueryRecords int `yaml:"max_query_records"`
+	// 批次返回的记录数少于预期时, 获取完整的 UID 列表并按 UID 补齐缺失的记录
+	Reconcile bool `yaml:"reconcile"`
 }
 
 func NewEntrezSettings() *EntrezSettings {
@@ -32,10 +43,14 @@ func NewEntrezSettings() *EntrezSettings {
 		RetMode:    "xml",
 		UseHistory: true,
 		SearchType: "gene symbol",
+		Assembly:   "GRCh38",
+		HGNCFile:   "",
 		Email:      "",
 		ToolName:   entrezSettingsToolName,
 		ApiKey:     "",
 		BatchSize:  10,
+		// 与 config.DefaultMaxQueryRecords 保持一致
+		MaxQueryRecords: 20000,
 	}
 }
 
@@ -0,0 +1,162 @@
+package configs
+
+import (
+	"fmt"
+	"github.com/iEchoxu/clinvarDL/pkg/cdlerror"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/input"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// invalidTaskNameChars 匹配任务名中不能用于文件名的字符
+var invalidTaskNameChars = regexp.MustCompile(`[^\w.-]`)
+
+// TasksConfig 定义多任务文件, 一次运行中依次执行所有任务
+type TasksConfig struct {
+	Path  string        `yaml:"-"`
+	Tasks []*TaskConfig `yaml:"tasks"`
+}
+
+// TaskConfig 定义单个任务
+type TaskConfig struct {
+	Name       string    `yaml:"name"`        // 任务名, 用于日志及默认输出文件名
+	SearchType string    `yaml:"search_type"` // 查询类型, 为空时使用配置文件中的 search_type
+	Queries    []string  `yaml:"queries"`     // 检索词列表, 与 file 二选一
+	File       string    `yaml:"file"`        // 任务文件路径, 相对路径基于任务文件所在目录, 为 - 时从标准输入读取
+	Format     string    `yaml:"format"`      // 任务文件格式, 为空时根据文件扩展名识别
+	Column     string    `yaml:"column"`      // csv/tsv/xlsx 任务文件中检索词所在列, 为空时使用命令行的 --column
+	InputSheet string    `yaml:"input_sheet"` // xlsx 任务文件的工作表名, 为空时使用命令行的 --sheet
+	Header     *bool     `yaml:"header"`      // csv/tsv/xlsx 任务文件首行是否为表头, 未设置时使用命令行的 --header
+	Filters    yaml.Node `yaml:"filters"`     // 覆盖 filters.yaml 中的过滤条件, 格式与 filters.yaml 相同
+	Output     string    `yaml:"output"`      // 输出文件名, 为空时使用 <name>_<时间戳>.xlsx
+	Sheet      string    `yaml:"sheet"`       // 工作表名, 为空时使用 ClinVar Results
+}
+
+// NewTasksConfig 创建并返回一个 TasksConfig 配置
+func NewTasksConfig() *TasksConfig {
+	return &TasksConfig{
+		Tasks: make([]*TaskConfig, 0),
+	}
+}
+
+func (tc *TasksConfig) Write(configFile string) error {
+	return write(tc, configFile)
+}
+
+func (tc *TasksConfig) Read(configFile string) (Configer, error) {
+	tc.Path = configFile
+	return read(configFile, tc)
+}
+
+// LoadTasks 读取并校验多任务文件
+func LoadTasks(configFile string) (*TasksConfig, error) {
+	tc := NewTasksConfig()
+	if _, err := tc.Read(configFile); err != nil {
+		return nil, err
+	}
+
+	if err := tc.validate(); err != nil {
+		return nil, err
+	}
+
+	return tc, nil
+}
+
+// validate 校验任务配置并补全默认值
+func (tc *TasksConfig) validate() error {
+	if len(tc.Tasks) == 0 {
+		return errors.Wrapf(cdlerror.ErrConfigParseFailed, "no tasks found in %s", tc.Path)
+	}
+
+	names := make(map[string]struct{}, len(tc.Tasks))
+	outputs := make(map[string]string, len(tc.Tasks))
+	for i, task := range tc.Tasks {
+		if task == nil {
+			return errors.Wrapf(cdlerror.ErrConfigParseFailed, "task #%d is empty", i+1)
+		}
+
+		if task.Name == "" {
+			task.Name = fmt.Sprintf("task%d", i+1)
+		}
+		if _, ok := names[task.Name]; ok {
+			return errors.Wrapf(cdlerror.ErrConfigParseFailed, "duplicate task name '%s'", task.Name)
+		}
+		names[task.Name] = struct{}{}
+
+		if (task.File == "") == (len(task.Queries) == 0) {
+			return errors.Wrapf(cdlerror.ErrConfigParseFailed, "task '%s' must set exactly one of 'queries' or 'file'", task.Name)
+		}
+
+		// 相对路径基于任务文件所在目录
+		if task.File != "" && task.File != input.Stdin && !filepath.IsAbs(task.File) {
+			task.File = filepath.Join(filepath.Dir(tc.Path), task.File)
+		}
+
+		// 多个任务不能写入同一个输出文件
+		if task.Output != "" {
+			if other, ok := outputs[task.Output]; ok {
+				return errors.Wrapf(cdlerror.ErrConfigParseFailed, "tasks '%s' and '%s' use the same output '%s'", other, task.Name, task.Output)
+			}
+			outputs[task.Output] = task.Name
+		}
+	}
+
+	return nil
+}
+
+// FileName 返回可用作文件名的任务名
+func (t *TaskConfig) FileName() string {
+	return invalidTaskNameChars.ReplaceAllString(t.Name, "_")
+}
+
+// OutputFile 返回任务的输出文件名, 未设置时使用任务名及时间戳
+func (t *TaskConfig) OutputFile(timestamp string) string {
+	if t.Output == "" {
+		return fmt.Sprintf("%s_%s.xlsx", t.FileName(), timestamp)
+	}
+
+	if !strings.EqualFold(filepath.Ext(t.Output), ".xlsx") {
+		return t.Output + ".xlsx"
+	}
+
+	return t.Output
+}
+
+// TableOptions 在命令行解析选项的基础上应用任务中设置的列、工作表及表头选项
+func (t *TaskConfig) TableOptions(base input.TableOptions) input.TableOptions {
+	if t.Column != "" {
+		base.Column = t.Column
+	}
+	if t.InputSheet != "" {
+		base.Sheet = t.InputSheet
+	}
+	if t.Header != nil {
+		base.Header = *t.Header
+	}
+
+	return base
+}
+
+// BuildFilters 在全局过滤条件的基础上应用任务中的覆盖项, 返回检索式中的过滤条件
+// 任务未设置 filters 时直接使用全局过滤条件
+func (t *TaskConfig) BuildFilters(base *FiltersConfig) (string, error) {
+	if t.Filters.IsZero() {
+		return base.BuildQueryStringWithTerm(), nil
+	}
+
+	// 未出现在任务中的过滤项保留全局配置中的值
+	filtersConfig, err := base.load()
+	if err != nil {
+		return "", err
+	}
+
+	if err := t.Filters.Decode(filtersConfig); err != nil {
+		return "", errors.Wrapf(cdlerror.ErrConfigParseFailed, "failed to parse filters of task '%s'|%s", t.Name, err)
+	}
+
+	return filtersConfig.BuildQueryString(), nil
+}
@@ -0,0 +1,369 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+const (
+	batchDirName      = "batches"       // 批次缓存所在的子目录
+	batchManifestName = "manifest.json" // 记录批次缓存对应的 esearch 记录数
+)
+
+// BatchCache 定义按 esummary 批次保存结果的缓存
+// 批次完成后立即保存, 查询中断或超时后再次执行时只请求缺失的批次
+// 查询结果缓存只保留 VariationID, 文档摘要只保存在批次缓存中, 命中时按批次读取, 不需要一次性加载整个查询的结果
+// 批次按 (缓存键, esearch 记录数, retstart, retmax) 区分, esearch 记录数变化时该查询的全部批次失效
+type BatchCache interface {
+	// GetBatch 获取已完成的批次, count 与缓存时的 esearch 记录数不同时删除该查询的全部批次
+	GetBatch(key string, count, start, size int) (*types.ESummaryResult, error)
+
+	// SetBatch 保存已完成的批次
+	SetBatch(key string, count, start, size int, result *types.ESummaryResult) error
+
+	// EachBatch 按 retstart 顺序读取查询的全部批次, 批次缓存不存在或已过期时返回错误
+	EachBatch(key string, fn func(result *types.ESummaryResult) error) error
+
+	// DeleteBatches 删除查询的全部批次
+	DeleteBatches(key string) error
+}
+
+// batchManifest 定义单个查询的批次缓存信息
+type batchManifest struct {
+	Count        int       `json:"count"` // 缓存批次时 esearch 返回的记录数
+	CreatedAt    time.Time `json:"created_at"`
+	CacheVersion int       `json:"cache_version"`
+}
+
+// GetBatch 实现 BatchCache 接口
+func (c *FileCache) GetBatch(key string, count, start, size int) (*types.ESummaryResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	manifest, err := c.loadManifest(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.manifestValid(manifest) {
+		c.removeBatches(key)
+		return nil, fmt.Errorf("cached batches expired for query '%v'", key)
+	}
+
+	// 记录数变化时批次的 retstart 不再对应相同的记录
+	if manifest.Count != count {
+		c.removeBatches(key)
+		logcdl.Warn("record count changed for query '%v' (%d -> %d), discarding cached batches", key, manifest.Count, count)
+		return nil, fmt.Errorf("record count changed for query '%v': %d -> %d", key, manifest.Count, count)
+	}
+
+	data, err := os.ReadFile(c.batchPath(key, start, size))
+	if err != nil {
+		return nil, err
+	}
+
+	var result types.ESummaryResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.DocumentSummarySet.DocumentSummary) == 0 {
+		return nil, fmt.Errorf("invalid cached batch (start=%d) for query '%v': empty result", start, key)
+	}
+
+	c.live.add(key)
+	return &result, nil
+}
+
+// SetBatch 实现 BatchCache 接口, 批次计入缓存的总大小, 超过 MaxSize 时淘汰其他缓存
+func (c *FileCache) SetBatch(key string, count, start, size int, result *types.ESummaryResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if result == nil || len(result.DocumentSummarySet.DocumentSummary) == 0 {
+		return fmt.Errorf("invalid batch entry: empty result")
+	}
+
+	c.live.add(key)
+
+	// 记录数变化或批次缓存已过期时重新开始缓存该查询的批次
+	manifest, err := c.loadManifest(key)
+	if err != nil || manifest.Count != count || !c.manifestValid(manifest) {
+		c.removeBatches(key)
+		if err := os.MkdirAll(c.batchDir(key), 0755); err != nil {
+			return err
+		}
+
+		manifest = &batchManifest{Count: count, CreatedAt: time.Now(), CacheVersion: FormatVersion}
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(filepath.Join(c.batchDir(key), batchManifestName), data); err != nil {
+			return err
+		}
+		c.growBatches(key, manifest.CreatedAt, int64(len(data)))
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	// 重新请求的批次覆盖原有文件, 只计入大小的变化
+	path := c.batchPath(key, start, size)
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	c.growBatches(key, manifest.CreatedAt, int64(len(data))-previous)
+	c.evict(key)
+
+	return nil
+}
+
+// EachBatch 实现 BatchCache 接口, 每次只读取一个批次, 调用 fn 时不持有锁
+func (c *FileCache) EachBatch(key string, fn func(result *types.ESummaryResult) error) error {
+	paths, err := c.batchPaths(key)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		c.mu.Lock()
+		data, err := os.ReadFile(path)
+		c.mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		var result types.ESummaryResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return err
+		}
+		if err := fn(&result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchPaths 返回查询的全部批次缓存文件, 按 retstart 排序
+func (c *FileCache) batchPaths(key string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	manifest, err := c.loadManifest(key)
+	if err != nil {
+		return nil, err
+	}
+	if !c.manifestValid(manifest) {
+		return nil, fmt.Errorf("cached batches expired for query '%v'", key)
+	}
+
+	entries, err := os.ReadDir(c.batchDir(key))
+	if err != nil {
+		return nil, err
+	}
+
+	starts := make(map[string]int, len(entries))
+	var names []string
+	for _, entry := range entries {
+		start, ok := batchStart(entry.Name())
+		if !ok {
+			continue
+		}
+		starts[entry.Name()] = start
+		names = append(names, entry.Name())
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return starts[names[i]] < starts[names[j]]
+	})
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, filepath.Join(c.batchDir(key), name))
+	}
+
+	return paths, nil
+}
+
+// DeleteBatches 实现 BatchCache 接口
+func (c *FileCache) DeleteBatches(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.forgetBatches(key)
+	return os.RemoveAll(c.batchDir(key))
+}
+
+// cleanExpiredBatches 删除过期及旧版本格式的批次缓存, 返回删除的查询数, 调用前需要持有锁
+func (c *FileCache) cleanExpiredBatches() int {
+	entries, err := os.ReadDir(filepath.Join(c.CacheDir, batchDirName))
+	if err != nil {
+		return 0
+	}
+
+	var removed int
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		// 缺少批次信息的目录来自中断的写入, 同样删除
+		manifest, err := c.loadManifest(entry.Name())
+		if err != nil || !c.manifestValid(manifest) {
+			c.removeBatches(entry.Name())
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// manifestValid 检查批次缓存是否过期及格式版本是否一致
+func (c *FileCache) manifestValid(manifest *batchManifest) bool {
+	return manifest.CacheVersion == FormatVersion && time.Since(manifest.CreatedAt) <= c.TTL
+}
+
+// loadManifest 加载查询的批次缓存信息
+func (c *FileCache) loadManifest(key string) (*batchManifest, error) {
+	data, err := os.ReadFile(filepath.Join(c.batchDir(key), batchManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest batchManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// removeBatches 删除查询的全部批次, 调用前需要持有锁
+func (c *FileCache) removeBatches(key string) {
+	c.forgetBatches(key)
+	if err := os.RemoveAll(c.batchDir(key)); err != nil {
+		logcdl.Warn("failed to remove cached batches for query '%v': %v", key, err)
+	}
+}
+
+// scanBatches 统计已有批次缓存的大小, 调用前需要持有锁
+func (c *FileCache) scanBatches() {
+	entries, err := os.ReadDir(filepath.Join(c.CacheDir, batchDirName))
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		var createdAt time.Time
+		if manifest, err := c.loadManifest(entry.Name()); err == nil {
+			createdAt = manifest.CreatedAt
+		}
+
+		files, err := os.ReadDir(c.batchDir(entry.Name()))
+		if err != nil {
+			continue
+		}
+		var size int64
+		for _, file := range files {
+			if info, err := file.Info(); err == nil && !info.IsDir() {
+				size += info.Size()
+			}
+		}
+		c.growBatches(entry.Name(), createdAt, size)
+	}
+}
+
+// growBatches 记录查询的批次缓存大小的变化, 调用前需要持有锁
+func (c *FileCache) growBatches(key string, createdAt time.Time, delta int64) {
+	set, ok := c.batchSets[key]
+	if !ok {
+		set = &batchSet{createdAt: createdAt}
+		c.batchSets[key] = set
+	}
+	set.size += delta
+	c.diskBytes += delta
+}
+
+// forgetBatches 不再记录查询的批次缓存大小, 调用前需要持有锁
+func (c *FileCache) forgetBatches(key string) {
+	if set, ok := c.batchSets[key]; ok {
+		c.diskBytes -= set.size
+		delete(c.batchSets, key)
+	}
+}
+
+// batchSetKeys 返回缓存的文档摘要所在的批次缓存键, 包括缓存键本身
+// 拆分查询的文档摘要保存在各子查询的批次缓存中
+func batchSetKeys(key string, batchKeys []string) []string {
+	keys := []string{key}
+	for _, batchKey := range batchKeys {
+		if batchKey != key {
+			keys = append(keys, batchKey)
+		}
+	}
+	return keys
+}
+
+// liveBatches 记录本次运行中读写过的批次缓存键
+// 正在执行的查询的批次尚未归属任何缓存, 淘汰缓存时不能视为未完成或已被替换的查询的批次
+type liveBatches struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// add 记录本次运行中读写过的批次缓存键
+func (l *liveBatches) add(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.keys == nil {
+		l.keys = make(map[string]struct{})
+	}
+	l.keys[key] = struct{}{}
+}
+
+// has 检查本次运行中是否读写过批次缓存
+func (l *liveBatches) has(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.keys[key]
+	return ok
+}
+
+// batchDir 返回查询的批次缓存目录
+func (c *FileCache) batchDir(key string) string {
+	return filepath.Join(c.CacheDir, batchDirName, key)
+}
+
+// batchStart 从批次缓存的文件名或键 (start-size) 中解析 retstart, 不是批次时返回 false
+func batchStart(name string) (int, bool) {
+	var start, size int
+	if _, err := fmt.Sscanf(strings.TrimSuffix(name, cacheFileExt), "%d-%d", &start, &size); err != nil {
+		return 0, false
+	}
+	return start, true
+}
+
+// batchPath 返回单个批次的缓存文件路径
+func (c *FileCache) batchPath(key string, start, size int) string {
+	return filepath.Join(c.batchDir(key), fmt.Sprintf("%d-%d%s", start, size, cacheFileExt))
+}
@@ -0,0 +1,819 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltFileName 单文件缓存数据库的文件名, 位于缓存目录中
+const BoltFileName = "cache.db"
+
+var (
+	queriesBucket  = []byte("queries")  // 缓存键 -> 压缩后的查询结果
+	metaBucket     = []byte("meta")     // 缓存键 -> 缓存信息 (创建时间、访问时间、大小等)
+	uidsBucket     = []byte("uids")     // 缓存键 -> 压缩后的 VariationID 列表, 删除缓存时用于清理索引
+	variantsBucket = []byte("variants") // VariationID + "\x00" + 缓存键 -> 空值, 按 VariationID 查找缓存
+	batchesBucket  = []byte("batches")  // 缓存键 -> 批次子桶 (批次信息及压缩后的批次结果), 保存查询结果的文档摘要
+
+	batchManifestKey = []byte("manifest")
+
+	boltBuckets = [][]byte{queriesBucket, metaBucket, uidsBucket, variantsBucket, batchesBucket}
+)
+
+// BoltCache 实现基于单个 bbolt 数据库文件的缓存
+// 查询结果只保留 VariationID, 文档摘要按批次压缩后保存, 每次写入在单个事务中完成; 同时按 VariationID 建立索引, 可在所有缓存的查询中查找单个变异
+// 批次缓存同样计入 MaxSize, 超过时先从最早创建的开始删除不属于任何缓存的批次, 再删除最久未访问的缓存及其批次
+// 本次运行中读写过的批次属于正在执行的查询, 不会作为不属于任何缓存的批次删除
+// 数据库文件同一时间只能由一个进程打开
+type BoltCache struct {
+	Path    string        // 数据库文件路径
+	TTL     time.Duration // 缓存过期时间
+	MaxSize int64         // 压缩后的查询结果及批次的最大总大小 (字节), 0 表示不限制
+
+	db   *bolt.DB
+	live liveBatches // 本次运行中读写过的批次缓存键
+}
+
+// boltMeta 定义单条缓存的信息, 列出及淘汰缓存时不需要解压查询结果
+type boltMeta struct {
+	QueryID      string            `json:"query_id"`
+	Query        string            `json:"query"`
+	Status       types.QueryStatus `json:"status"`
+	Progress     string            `json:"progress"`
+	CreatedAt    time.Time         `json:"created_at"`
+	AccessedAt   time.Time         `json:"accessed_at"`
+	Size         int64             `json:"size"` // 压缩后的查询结果大小
+	CacheVersion int               `json:"cache_version"`
+	BatchKeys    []string          `json:"batch_keys,omitempty"` // 保存文档摘要的批次缓存键, 删除缓存时一并删除
+}
+
+// NewBoltCache 打开 (或创建) 缓存目录中的数据库文件
+func NewBoltCache(cacheDir string, ttl time.Duration, maxSize int64) (*BoltCache, error) {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(cacheDir, BoltFileName)
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		if err == bolt.ErrTimeout {
+			return nil, fmt.Errorf("cache database '%s' is in use by another process", path)
+		}
+		return nil, fmt.Errorf("failed to open cache database '%s': %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range boltBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cache database '%s': %v", path, err)
+	}
+
+	return &BoltCache{
+		Path:    path,
+		TTL:     ttl,
+		MaxSize: maxSize,
+		db:      db,
+	}, nil
+}
+
+// Get 实现 Cache 接口
+func (c *BoltCache) Get(key string) (*types.QueryResult, error) {
+	var entry types.QueryResult
+	var invalid error
+	err := c.db.View(func(tx *bolt.Tx) error {
+		meta, err := loadBoltMeta(tx, key)
+		if err != nil {
+			return err
+		}
+
+		// 旧版本的缓存键及内容均不可信
+		if meta.CacheVersion != FormatVersion {
+			invalid = fmt.Errorf("outdated cache format version %d for query '%v'", meta.CacheVersion, key)
+			return nil
+		}
+		if time.Since(meta.CreatedAt) > c.TTL {
+			invalid = fmt.Errorf("cache expired for query '%v'", key)
+			return nil
+		}
+
+		return decompress(tx.Bucket(queriesBucket).Get([]byte(key)), &entry)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cache from database for query '%v': %w", key, err)
+	}
+
+	if invalid != nil {
+		if err := c.db.Update(func(tx *bolt.Tx) error { return deleteBoltEntry(tx, key) }); err != nil {
+			logcdl.Warn("failed to remove cache entry for query '%v': %v", key, err)
+		}
+		return nil, invalid
+	}
+
+	if entry.Result == nil {
+		return nil, fmt.Errorf("invalid cache entry loaded from database for query '%v': nil result", key)
+	}
+
+	// 记录访问时间, 淘汰时优先删除最久未访问的缓存
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		meta, err := loadBoltMeta(tx, key)
+		if err != nil {
+			return err
+		}
+		meta.AccessedAt = time.Now()
+		return putJSON(tx.Bucket(metaBucket), []byte(key), meta)
+	})
+	if err != nil {
+		logcdl.Debug("failed to update access time of cache entry for query '%v': %v", key, err)
+	}
+
+	logcdl.Info("loaded cache from database for query '%v'", key)
+
+	return &entry, nil
+}
+
+// Set 实现 Cache 接口
+// 查询结果、缓存信息及 VariationID 索引在同一事务中更新, 写入中断时不会留下不完整的缓存
+func (c *BoltCache) Set(key string, entry *types.QueryResult) error {
+	if entry == nil || entry.Result == nil {
+		return fmt.Errorf("invalid cache entry: nil result")
+	}
+
+	entry.SetCacheVersion(FormatVersion)
+
+	data, err := compress(entry)
+	if err != nil {
+		return err
+	}
+
+	uids := make([]string, 0, len(entry.Result.DocumentSummarySet.DocumentSummary))
+	for _, doc := range entry.Result.DocumentSummarySet.DocumentSummary {
+		uids = append(uids, doc.Uid)
+	}
+	uidData, err := compress(uids)
+	if err != nil {
+		return err
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		// 先删除旧的缓存及其索引, 批次缓存保存本次查询的文档摘要, 不删除
+		if err := deleteBoltIndex(tx, key); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(queriesBucket).Put([]byte(key), data); err != nil {
+			return err
+		}
+		if err := tx.Bucket(uidsBucket).Put([]byte(key), uidData); err != nil {
+			return err
+		}
+
+		variants := tx.Bucket(variantsBucket)
+		for _, uid := range uids {
+			if err := variants.Put(variantKey(uid, key), nil); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		meta := &boltMeta{
+			QueryID:      entry.QueryID,
+			Query:        entry.Query,
+			Status:       entry.Status,
+			Progress:     entry.Progress,
+			CreatedAt:    now,
+			AccessedAt:   now,
+			Size:         int64(len(data)),
+			CacheVersion: FormatVersion,
+			BatchKeys:    entry.BatchKeys,
+		}
+		if err := putJSON(tx.Bucket(metaBucket), []byte(key), meta); err != nil {
+			return err
+		}
+
+		return c.evict(tx, key)
+	})
+}
+
+// CleanExpired 实现 Cache 接口, 删除过期及旧版本格式的缓存及批次缓存
+func (c *BoltCache) CleanExpired() error {
+	var removed, removedBatches int
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var expired []string
+		err := tx.Bucket(metaBucket).ForEach(func(k, v []byte) error {
+			var meta boltMeta
+			if err := json.Unmarshal(v, &meta); err != nil || meta.CacheVersion != FormatVersion || time.Since(meta.CreatedAt) > c.TTL {
+				expired = append(expired, string(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := deleteBoltEntry(tx, key); err != nil {
+				return err
+			}
+		}
+		removed = len(expired)
+
+		// 清理未完成查询遗留的过期批次
+		var expiredBatches [][]byte
+		batches := tx.Bucket(batchesBucket)
+		err = batches.ForEach(func(k, _ []byte) error {
+			if bucket := batches.Bucket(k); bucket == nil || !c.manifestValid(bucket) {
+				expiredBatches = append(expiredBatches, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expiredBatches {
+			if err := batches.DeleteBucket(key); err != nil {
+				return err
+			}
+		}
+		removedBatches = len(expiredBatches)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clean expired cache: %v", err)
+	}
+
+	if removed > 0 {
+		logcdl.Info("removed %d expired cache entries from database", removed)
+	}
+	if removedBatches > 0 {
+		logcdl.Info("removed expired cached batches of %d queries", removedBatches)
+	}
+
+	return nil
+}
+
+// Close 实现 Cache 接口, 关闭数据库文件
+func (c *BoltCache) Close() error {
+	return c.db.Close()
+}
+
+// List 实现 Inspector 接口, 占用大小包括缓存的批次
+func (c *BoltCache) List() ([]*EntryInfo, error) {
+	var infos []*EntryInfo
+	err := c.db.View(func(tx *bolt.Tx) error {
+		batches := tx.Bucket(batchesBucket)
+		return tx.Bucket(metaBucket).ForEach(func(k, v []byte) error {
+			var meta boltMeta
+			if err := json.Unmarshal(v, &meta); err != nil {
+				logcdl.Warn("failed to unmarshal cache entry info for query '%s': %v", k, err)
+				return nil
+			}
+
+			size := meta.Size
+			for _, key := range batchSetKeys(string(k), meta.BatchKeys) {
+				if bucket := batches.Bucket([]byte(key)); bucket != nil {
+					size += boltBucketSize(bucket)
+				}
+			}
+
+			infos = append(infos, &EntryInfo{
+				Key:        string(k),
+				QueryID:    meta.QueryID,
+				Query:      meta.Query,
+				Status:     meta.Status,
+				Progress:   meta.Progress,
+				CreatedAt:  meta.CreatedAt,
+				AccessedAt: meta.AccessedAt,
+				Size:       size,
+				Expired:    meta.CacheVersion != FormatVersion || time.Since(meta.CreatedAt) > c.TTL,
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// Load 实现 Inspector 接口
+func (c *BoltCache) Load(key string) (*types.QueryResult, error) {
+	var entry types.QueryResult
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return decompress(tx.Bucket(queriesBucket).Get([]byte(key)), &entry)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cache from database for query '%v': %w", key, err)
+	}
+
+	return &entry, nil
+}
+
+// Delete 实现 Inspector 接口
+func (c *BoltCache) Delete(key string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return deleteBoltEntry(tx, key)
+	})
+}
+
+// Clear 实现 Inspector 接口, 删除后重新创建所有桶
+func (c *BoltCache) Clear() error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range boltBuckets {
+			if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LookupVariant 实现 VariantIndex 接口
+// 文档摘要保存在批次缓存中, 按查询结果记录的批次缓存键逐个批次查找
+func (c *BoltCache) LookupVariant(uid string) ([]*VariantEntry, error) {
+	var entries []*VariantEntry
+	err := c.db.View(func(tx *bolt.Tx) error {
+		prefix := variantKey(uid, "")
+		cursor := tx.Bucket(variantsBucket).Cursor()
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+			key := string(k[len(prefix):])
+
+			var result types.QueryResult
+			if err := decompress(tx.Bucket(queriesBucket).Get([]byte(key)), &result); err != nil {
+				return fmt.Errorf("failed to load cache entry '%s': %v", key, err)
+			}
+
+			doc, err := findBoltVariant(tx, result.BatchKeys, uid)
+			if err != nil {
+				return fmt.Errorf("failed to load cached batches of '%s': %v", key, err)
+			}
+			if doc != nil {
+				entries = append(entries, &VariantEntry{Key: key, QueryID: result.QueryID, Query: result.Query, Summary: doc})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// findBoltVariant 在批次缓存中查找 VariationID 对应的文档摘要, 未找到时返回 nil
+func findBoltVariant(tx *bolt.Tx, batchKeys []string, uid string) (*types.DocumentSummary, error) {
+	for _, key := range batchKeys {
+		bucket := tx.Bucket(batchesBucket).Bucket([]byte(key))
+		if bucket == nil {
+			continue
+		}
+
+		var found *types.DocumentSummary
+		err := bucket.ForEach(func(k, v []byte) error {
+			if found != nil || bytes.Equal(k, batchManifestKey) {
+				return nil
+			}
+
+			var batch types.ESummaryResult
+			if err := decompress(v, &batch); err != nil {
+				return err
+			}
+			for _, doc := range batch.DocumentSummarySet.DocumentSummary {
+				if doc.Uid == uid {
+					found = doc
+					break
+				}
+			}
+			return nil
+		})
+		if err != nil || found != nil {
+			return found, err
+		}
+	}
+
+	return nil, nil
+}
+
+// GetBatch 实现 BatchCache 接口
+func (c *BoltCache) GetBatch(key string, count, start, size int) (*types.ESummaryResult, error) {
+	var result types.ESummaryResult
+	var stale error
+	err := c.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(batchesBucket).Bucket([]byte(key))
+		if bucket == nil {
+			return fmt.Errorf("no cached batches for query '%v'", key)
+		}
+
+		if !c.manifestValid(bucket) {
+			stale = fmt.Errorf("cached batches expired for query '%v'", key)
+			return nil
+		}
+
+		// 记录数变化时批次的 retstart 不再对应相同的记录
+		var manifest batchManifest
+		if err := json.Unmarshal(bucket.Get(batchManifestKey), &manifest); err != nil {
+			return err
+		}
+		if manifest.Count != count {
+			logcdl.Warn("record count changed for query '%v' (%d -> %d), discarding cached batches", key, manifest.Count, count)
+			stale = fmt.Errorf("record count changed for query '%v': %d -> %d", key, manifest.Count, count)
+			return nil
+		}
+
+		data := bucket.Get(batchEntryKey(start, size))
+		if data == nil {
+			return fmt.Errorf("batch (start=%d) of query '%v' is not cached", start, key)
+		}
+
+		return decompress(data, &result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if stale != nil {
+		if err := c.DeleteBatches(key); err != nil {
+			logcdl.Warn("failed to remove cached batches for query '%v': %v", key, err)
+		}
+		return nil, stale
+	}
+
+	if len(result.DocumentSummarySet.DocumentSummary) == 0 {
+		return nil, fmt.Errorf("invalid cached batch (start=%d) for query '%v': empty result", start, key)
+	}
+
+	c.live.add(key)
+	return &result, nil
+}
+
+// SetBatch 实现 BatchCache 接口
+func (c *BoltCache) SetBatch(key string, count, start, size int, result *types.ESummaryResult) error {
+	if result == nil || len(result.DocumentSummarySet.DocumentSummary) == 0 {
+		return fmt.Errorf("invalid batch entry: empty result")
+	}
+
+	data, err := compress(result)
+	if err != nil {
+		return err
+	}
+
+	c.live.add(key)
+	return c.db.Update(func(tx *bolt.Tx) error {
+		batches := tx.Bucket(batchesBucket)
+
+		// 记录数变化或批次缓存已过期时重新开始缓存该查询的批次
+		bucket := batches.Bucket([]byte(key))
+		if bucket != nil {
+			var manifest batchManifest
+			if err := json.Unmarshal(bucket.Get(batchManifestKey), &manifest); err != nil || manifest.Count != count || !c.manifestValid(bucket) {
+				if err := batches.DeleteBucket([]byte(key)); err != nil {
+					return err
+				}
+				bucket = nil
+			}
+		}
+
+		if bucket == nil {
+			var err error
+			if bucket, err = batches.CreateBucket([]byte(key)); err != nil {
+				return err
+			}
+			manifest := &batchManifest{Count: count, CreatedAt: time.Now(), CacheVersion: FormatVersion}
+			if err := putJSON(bucket, batchManifestKey, manifest); err != nil {
+				return err
+			}
+		}
+
+		if err := bucket.Put(batchEntryKey(start, size), data); err != nil {
+			return err
+		}
+
+		return c.evict(tx, key)
+	})
+}
+
+// EachBatch 实现 BatchCache 接口, 每个批次在单独的只读事务中读取, 调用 fn 时不持有事务
+func (c *BoltCache) EachBatch(key string, fn func(result *types.ESummaryResult) error) error {
+	var entryKeys [][]byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(batchesBucket).Bucket([]byte(key))
+		if bucket == nil {
+			return fmt.Errorf("no cached batches for query '%v'", key)
+		}
+		if !c.manifestValid(bucket) {
+			return fmt.Errorf("cached batches expired for query '%v'", key)
+		}
+
+		return bucket.ForEach(func(k, _ []byte) error {
+			if _, ok := batchStart(string(k)); ok {
+				entryKeys = append(entryKeys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// 键按字节序排列, 按 retstart 重新排序
+	sort.Slice(entryKeys, func(i, j int) bool {
+		start1, _ := batchStart(string(entryKeys[i]))
+		start2, _ := batchStart(string(entryKeys[j]))
+		return start1 < start2
+	})
+
+	for _, entryKey := range entryKeys {
+		var result types.ESummaryResult
+		err := c.db.View(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket(batchesBucket).Bucket([]byte(key))
+			if bucket == nil {
+				return fmt.Errorf("cached batches of query '%v' were removed", key)
+			}
+			return decompress(bucket.Get(entryKey), &result)
+		})
+		if err != nil {
+			return err
+		}
+		if err := fn(&result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteBatches 实现 BatchCache 接口
+func (c *BoltCache) DeleteBatches(key string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(batchesBucket).DeleteBucket([]byte(key))
+		if err == bolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+// evict 压缩后的查询结果及批次的总大小超过 MaxSize 时删除缓存
+// 先从最早创建的开始删除不属于任何缓存且本次运行中未读写过的批次, 仍然超过时再删除最久未访问的缓存及其批次
+// keep 为刚写入的缓存或批次缓存的键, 不会被删除
+func (c *BoltCache) evict(tx *bolt.Tx, keep string) error {
+	if c.MaxSize <= 0 {
+		return nil
+	}
+
+	type entry struct {
+		key  string
+		meta boltMeta
+	}
+	type batchSet struct {
+		key       string
+		size      int64
+		createdAt time.Time
+	}
+
+	var entries []entry
+	var total int64
+	owned := make(map[string]bool)
+	err := tx.Bucket(metaBucket).ForEach(func(k, v []byte) error {
+		var meta boltMeta
+		if err := json.Unmarshal(v, &meta); err != nil {
+			return nil
+		}
+		entries = append(entries, entry{key: string(k), meta: meta})
+		total += meta.Size
+		for _, key := range batchSetKeys(string(k), meta.BatchKeys) {
+			owned[key] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	batches := tx.Bucket(batchesBucket)
+	batchSizes := make(map[string]int64)
+	var stale []batchSet
+	err = batches.ForEach(func(k, _ []byte) error {
+		bucket := batches.Bucket(k)
+		if bucket == nil {
+			return nil
+		}
+		size := boltBucketSize(bucket)
+		batchSizes[string(k)] = size
+		total += size
+
+		if !owned[string(k)] && !c.live.has(string(k)) {
+			// 缺少批次信息时创建时间为零值, 最先删除
+			var manifest batchManifest
+			json.Unmarshal(bucket.Get(batchManifestKey), &manifest)
+			stale = append(stale, batchSet{key: string(k), size: size, createdAt: manifest.CreatedAt})
+		}
+		return nil
+	})
+	if err != nil || total <= c.MaxSize {
+		return err
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].createdAt.Before(stale[j].createdAt)
+	})
+
+	var evictedBatches int
+	for _, set := range stale {
+		if total <= c.MaxSize {
+			break
+		}
+		if err := batches.DeleteBucket([]byte(set.key)); err != nil {
+			return err
+		}
+		total -= set.size
+		evictedBatches++
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].meta.AccessedAt.Before(entries[j].meta.AccessedAt)
+	})
+
+	var evicted int
+	for _, e := range entries {
+		if total <= c.MaxSize {
+			break
+		}
+		if e.key == keep {
+			continue
+		}
+		if err := deleteBoltEntry(tx, e.key); err != nil {
+			return err
+		}
+		total -= e.meta.Size
+		for _, key := range batchSetKeys(e.key, e.meta.BatchKeys) {
+			total -= batchSizes[key]
+			delete(batchSizes, key)
+		}
+		evicted++
+	}
+
+	if total > c.MaxSize {
+		logcdl.Warn("cache entry for query '%v' alone exceeds the cache max size of %d MB", keep, c.MaxSize>>20)
+	}
+	if evictedBatches > 0 {
+		logcdl.Info("evicted cached batches of %d unfinished or replaced queries to stay under %d MB (%.1f MB in use)",
+			evictedBatches, c.MaxSize>>20, float64(total)/(1<<20))
+	}
+	if evicted > 0 {
+		logcdl.Info("evicted %d least recently used cache entries to stay under %d MB (%.1f MB in use)",
+			evicted, c.MaxSize>>20, float64(total)/(1<<20))
+	}
+
+	return nil
+}
+
+// boltBucketSize 返回批次子桶中批次信息及全部批次的大小
+func boltBucketSize(bucket *bolt.Bucket) int64 {
+	var size int64
+	bucket.ForEach(func(k, v []byte) error {
+		size += int64(len(v))
+		return nil
+	})
+	return size
+}
+
+// manifestValid 检查批次缓存是否过期及格式版本是否一致
+func (c *BoltCache) manifestValid(bucket *bolt.Bucket) bool {
+	var manifest batchManifest
+	if err := json.Unmarshal(bucket.Get(batchManifestKey), &manifest); err != nil {
+		return false
+	}
+	return manifest.CacheVersion == FormatVersion && time.Since(manifest.CreatedAt) <= c.TTL
+}
+
+// deleteBoltEntry 删除单条缓存及其 VariationID 索引、批次缓存, 缓存不存在时直接返回
+func deleteBoltEntry(tx *bolt.Tx, key string) error {
+	batchKeys := []string{key}
+	if meta, err := loadBoltMeta(tx, key); err == nil {
+		batchKeys = batchSetKeys(key, meta.BatchKeys)
+	}
+
+	if err := deleteBoltIndex(tx, key); err != nil {
+		return err
+	}
+
+	// 查询结果的文档摘要保存在批次缓存中, 一并删除
+	batches := tx.Bucket(batchesBucket)
+	for _, batchKey := range batchKeys {
+		if err := batches.DeleteBucket([]byte(batchKey)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteBoltIndex 删除单条缓存的查询结果、缓存信息及 VariationID 索引, 不删除批次缓存
+func deleteBoltIndex(tx *bolt.Tx, key string) error {
+	if data := tx.Bucket(uidsBucket).Get([]byte(key)); data != nil {
+		var uids []string
+		if err := decompress(data, &uids); err != nil {
+			return err
+		}
+		variants := tx.Bucket(variantsBucket)
+		for _, uid := range uids {
+			if err := variants.Delete(variantKey(uid, key)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range [][]byte{queriesBucket, metaBucket, uidsBucket} {
+		if err := tx.Bucket(name).Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadBoltMeta 加载单条缓存的信息
+func loadBoltMeta(tx *bolt.Tx, key string) (*boltMeta, error) {
+	data := tx.Bucket(metaBucket).Get([]byte(key))
+	if data == nil {
+		return nil, fmt.Errorf("no cache entry for query '%v'", key)
+	}
+
+	var meta boltMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
+// putJSON 以 JSON 格式写入桶
+func putJSON(bucket *bolt.Bucket, key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+// variantKey 返回 VariationID 索引的键, key 为空时返回该 VariationID 的前缀
+func variantKey(uid, key string) []byte {
+	return []byte(uid + "\x00" + key)
+}
+
+// batchEntryKey 返回单个批次在批次子桶中的键
+func batchEntryKey(start, size int) []byte {
+	return []byte(fmt.Sprintf("%d-%d", start, size))
+}
+
+// compress 将数据编码为 JSON 后使用 gzip 压缩
+func compress(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress 解压 gzip 数据并解析 JSON
+func decompress(data []byte, v any) error {
+	if data == nil {
+		return fmt.Errorf("no data")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	return json.NewDecoder(zr).Decode(v)
+}
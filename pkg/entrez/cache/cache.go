@@ -1,15 +1,78 @@
 package cache
 
-import "github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+import (
+	"fmt"
+	"time"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
 
 // Cache 定义缓存接口
 type Cache interface {
-	// Get 获取缓存的查询结果
-	Get(queryID string) (*types.QueryResult, error)
+	// Get 获取缓存的查询结果, key 由 Fingerprint.Key 生成
+	Get(key string) (*types.QueryResult, error)
 
 	// Set 设置查询结果缓存
-	Set(queryID string, entry *types.QueryResult) error
+	Set(key string, entry *types.QueryResult) error
 
 	// CleanExpired 清理过期的缓存
 	CleanExpired() error
+
+	// Close 释放缓存占用的资源 (如数据库文件)
+	Close() error
+}
+
+// Inspector 定义查看及管理缓存的接口, 供 cache 命令使用
+type Inspector interface {
+	// List 返回所有缓存的信息, 过期的缓存同样列出
+	List() ([]*EntryInfo, error)
+
+	// Load 返回缓存的查询结果, 不检查是否过期, 也不更新访问时间
+	Load(key string) (*types.QueryResult, error)
+
+	// Delete 删除单条缓存及其批次缓存
+	Delete(key string) error
+
+	// Clear 删除全部缓存及批次缓存
+	Clear() error
+}
+
+// EntryInfo 定义单条缓存的信息
+type EntryInfo struct {
+	Key        string            // 缓存键
+	QueryID    string            // 查询ID
+	Query      string            // 查询内容
+	Status     types.QueryStatus // 查询状态
+	Progress   string            // 进度
+	CreatedAt  time.Time         // 创建时间
+	AccessedAt time.Time         // 最近访问时间
+	Size       int64             // 占用大小 (字节), 包括文档摘要所在的批次缓存
+	Expired    bool              // 是否过期或为旧版本格式
+}
+
+// VariantIndex 定义按 VariationID 查找缓存的接口
+type VariantIndex interface {
+	// LookupVariant 返回所有包含该 VariationID 的缓存查询及对应的文档摘要
+	LookupVariant(uid string) ([]*VariantEntry, error)
+}
+
+// VariantEntry 定义单个缓存查询中的变异
+type VariantEntry struct {
+	Key     string                 // 缓存键
+	QueryID string                 // 查询ID
+	Query   string                 // 查询内容
+	Summary *types.DocumentSummary // 文档摘要
+}
+
+// New 根据配置的后端创建缓存
+func New(cfg *config.CacheConfig) (Cache, error) {
+	switch cfg.Backend {
+	case config.CacheBackendBolt:
+		return NewBoltCache(cfg.Dir, cfg.TTL, cfg.MaxSize)
+	case config.CacheBackendFile, "":
+		return NewFileCache(cfg.Dir, cfg.TTL, WithMaxSize(cfg.MaxSize), WithMaxEntries(cfg.MaxEntries))
+	default:
+		return nil, fmt.Errorf("unsupported cache backend '%s'", cfg.Backend)
+	}
 }
@@ -1,10 +1,12 @@
 package cache
 
 import (
+	"container/list"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,43 +15,136 @@ import (
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
 )
 
+const (
+	cacheFileExt            = ".json"
+	defaultMaxMemoryEntries = 32 // 内存中默认最多保留的缓存条目数
+)
+
 // FileCache 实现基于文件的缓存
+// 磁盘上的缓存文件按最近访问时间 (文件修改时间) 排序, 总大小超过 MaxSize 时删除最久未访问的缓存
+// 内存中只保留最近访问的 maxEntries 条缓存, 与磁盘上的缓存分开淘汰
+// 缓存文件只保留查询信息及 VariationID, 文档摘要按批次保存在 batches 子目录中, 删除缓存时一并删除
+// 批次缓存同样计入 MaxSize, 超过时先从最早创建的开始删除不属于任何缓存的批次 (之前运行中未完成或已被替换的查询), 再删除最久未访问的缓存
+// 本次运行中读写过的批次属于正在执行的查询, 不会作为不属于任何缓存的批次删除
 type FileCache struct {
-	CacheDir string                        // 缓存目录
-	Data     map[string]*types.QueryResult // 缓存数据
-	mu       sync.RWMutex                  // 读写锁
-	TTL      time.Duration                 // 缓存过期时间
+	CacheDir string        // 缓存目录
+	TTL      time.Duration // 缓存过期时间
+	MaxSize  int64         // 缓存文件及批次缓存的最大总大小 (字节), 0 表示不限制
+
+	mu         sync.Mutex
+	files      map[string]*list.Element // 缓存键 -> 磁盘 LRU 中的元素
+	fileLRU    *list.List               // 磁盘上的缓存文件, 最前面为最近访问的缓存
+	batchSets  map[string]*batchSet     // 批次缓存键 -> 磁盘上的批次缓存
+	live       liveBatches              // 本次运行中读写过的批次缓存键
+	diskBytes  int64                    // 缓存文件及批次缓存的总大小
+	memory     map[string]*list.Element // 缓存键 -> 内存 LRU 中的元素
+	memoryLRU  *list.List               // 内存中的缓存数据, 最前面为最近访问的缓存
+	maxEntries int                      // 内存中最多保留的缓存条目数
+}
+
+// cacheFile 定义磁盘上的单个缓存文件
+type cacheFile struct {
+	key       string
+	size      int64
+	batchKeys []string // 保存文档摘要的批次缓存键, 删除缓存时一并删除
+}
+
+// batchSet 定义磁盘上单个查询的批次缓存
+type batchSet struct {
+	size      int64     // 批次信息及全部批次文件的总大小
+	createdAt time.Time // 开始缓存批次的时间
+}
+
+// memoryEntry 定义内存中的单条缓存
+type memoryEntry struct {
+	key   string
+	entry *types.QueryResult
+}
+
+// cacheHeader 只解析缓存文件中判断是否有效及列出缓存需要的字段, 不需要解析完整的结果
+type cacheHeader struct {
+	QueryID      string            `json:"query_id"`
+	Query        string            `json:"query"`
+	Status       types.QueryStatus `json:"status"`
+	Progress     string            `json:"progress"`
+	CreatedAt    time.Time         `json:"created_at"`
+	CacheVersion int               `json:"cache_version"`
+	BatchKeys    []string          `json:"batch_keys"`
+}
+
+// FileCacheOption 定义文件缓存的可选配置
+type FileCacheOption func(c *FileCache)
+
+// WithMaxSize 设置缓存文件的最大总大小 (字节)
+func WithMaxSize(size int64) FileCacheOption {
+	return func(c *FileCache) {
+		if size > 0 {
+			c.MaxSize = size
+		}
+	}
+}
+
+// WithMaxEntries 设置内存中最多保留的缓存条目数
+func WithMaxEntries(entries int) FileCacheOption {
+	return func(c *FileCache) {
+		if entries > 0 {
+			c.maxEntries = entries
+		}
+	}
 }
 
 // NewFileCache 创建新的文件缓存
-func NewFileCache(cacheDir string, ttl time.Duration) (*FileCache, error) {
+// 创建时扫描缓存目录统计已有缓存文件及批次缓存的大小, 超过 MaxSize 时立即淘汰
+func NewFileCache(cacheDir string, ttl time.Duration, opts ...FileCacheOption) (*FileCache, error) {
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return nil, err
 	}
 
-	return &FileCache{
-		CacheDir: cacheDir,
-		Data:     make(map[string]*types.QueryResult),
-		TTL:      ttl,
-	}, nil
+	c := &FileCache{
+		CacheDir:   cacheDir,
+		TTL:        ttl,
+		files:      make(map[string]*list.Element),
+		fileLRU:    list.New(),
+		batchSets:  make(map[string]*batchSet),
+		memory:     make(map[string]*list.Element),
+		memoryLRU:  list.New(),
+		maxEntries: defaultMaxMemoryEntries,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := c.scan(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.evict("")
+	c.mu.Unlock()
+
+	return c, nil
 }
 
 // Get 实现 Cache 接口
 func (c *FileCache) Get(queryID string) (*types.QueryResult, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	// 先从内存缓存中查找
-	if entry, ok := c.Data[queryID]; ok {
+	if elem, ok := c.memory[queryID]; ok {
+		entry := elem.Value.(*memoryEntry).entry
 		// 检查是否过期
 		if time.Since(entry.CreatedAt) > c.TTL {
-			delete(c.Data, queryID)
+			c.remove(queryID)
 			return nil, fmt.Errorf("cache expired for query '%v'", queryID)
 		}
 		// 检查结果是否有效
 		if entry.Result == nil {
 			return nil, fmt.Errorf("invalid cache entry for query '%v': nil result", queryID)
 		}
+		c.memoryLRU.MoveToFront(elem)
+		c.touch(queryID)
 		return entry, nil
 	}
 
@@ -59,13 +154,16 @@ func (c *FileCache) Get(queryID string) (*types.QueryResult, error) {
 		return nil, fmt.Errorf("failed to load cache from file for query '%v': %w", queryID, err)
 	}
 
+	// 检查缓存格式版本, 旧版本的缓存键及内容均不可信
+	if entry.CacheVersion != FormatVersion {
+		c.remove(queryID)
+		return nil, fmt.Errorf("outdated cache format version %d for query '%v'", entry.CacheVersion, queryID)
+	}
+
 	// 检查是否过期
 	if time.Since(entry.CreatedAt) > c.TTL {
 		// 删除过期的缓存文件
-		filePath := filepath.Join(c.CacheDir, queryID+".json")
-		if err := os.Remove(filePath); err != nil {
-			logcdl.Warn("failed to remove expired cache file for query '%v': %v", queryID, err)
-		}
+		c.remove(queryID)
 		return nil, fmt.Errorf("cache expired for query '%v'", queryID)
 	}
 
@@ -75,7 +173,8 @@ func (c *FileCache) Get(queryID string) (*types.QueryResult, error) {
 	}
 
 	// 加载到内存缓存
-	c.Data[queryID] = entry
+	c.remember(queryID, entry)
+	c.touch(queryID)
 	logcdl.Info("loaded cache from file for query '%v'", queryID)
 
 	return entry, nil
@@ -91,88 +190,405 @@ func (c *FileCache) Set(queryID string, entry *types.QueryResult) error {
 		return fmt.Errorf("invalid cache entry: nil result")
 	}
 
-	// 更新内存缓存
-	c.Data[queryID] = entry
+	entry.SetCacheVersion(FormatVersion)
 
 	// 保存到文件
-	return c.saveToFile(queryID, entry)
+	size, err := c.saveToFile(queryID, entry)
+	if err != nil {
+		return err
+	}
+
+	// 更新内存缓存
+	c.remember(queryID, entry)
+
+	// 记录文件大小, 超过最大大小时淘汰其他缓存
+	c.track(queryID, size, entry.BatchKeys)
+	c.evict(queryID)
+
+	return nil
 }
 
-// loadFromFile 从文件加载缓存
-func (c *FileCache) loadFromFile(queryID string) (*types.QueryResult, error) {
-	filePath := filepath.Join(c.CacheDir, queryID+".json")
-	data, err := os.ReadFile(filePath)
+// CleanExpired 清理所有过期的缓存及批次缓存
+func (c *FileCache) CleanExpired() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 遍历缓存目录
+	entries, err := os.ReadDir(c.CacheDir)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to read cache directory: %v", err)
 	}
 
-	var entry types.QueryResult
-	if err := json.Unmarshal(data, &entry); err != nil {
-		return nil, err
-	}
+	now := time.Now()
+	var removed int
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileExt) {
+			continue
+		}
 
-	// 检查 Result  是否为 nil
-	if entry.Result == nil {
-		logcdl.Warn("invalid cache data for query '%v': nil result", queryID)
-		return nil, fmt.Errorf("invalid cache data: nil result")
+		// 加载缓存文件
+		filePath := filepath.Join(c.CacheDir, entry.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			logcdl.Warn("failed to read cache file %s: %v", filePath, err)
+			continue
+		}
+
+		var header cacheHeader
+		if err := json.Unmarshal(data, &header); err != nil {
+			logcdl.Warn("failed to unmarshal cache file %s: %v", filePath, err)
+			continue
+		}
+
+		// 检查是否过期, 旧版本格式的缓存同样删除
+		if header.CacheVersion != FormatVersion || now.Sub(header.CreatedAt) > c.TTL {
+			c.remove(strings.TrimSuffix(entry.Name(), cacheFileExt))
+			removed++
+		}
 	}
 
-	return &entry, nil
-}
+	if removed > 0 {
+		logcdl.Info("removed %d expired cache files, %.1f MB in use", removed, float64(c.diskBytes)/(1<<20))
+	}
 
-// saveToFile 保存缓存到文件
-func (c *FileCache) saveToFile(queryID string, entry *types.QueryResult) error {
-	data, err := json.Marshal(entry)
-	if err != nil {
-		return err
+	// 清理未完成查询遗留的过期批次
+	if removed := c.cleanExpiredBatches(); removed > 0 {
+		logcdl.Info("removed expired cached batches of %d queries", removed)
 	}
 
-	filePath := filepath.Join(c.CacheDir, queryID+".json")
-	return os.WriteFile(filePath, data, 0644)
+	return nil
 }
 
-// CleanExpired 清理所有过期的缓存
-func (c *FileCache) CleanExpired() error {
+// List 实现 Inspector 接口
+func (c *FileCache) List() ([]*EntryInfo, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// 遍历缓存目录
 	entries, err := os.ReadDir(c.CacheDir)
 	if err != nil {
-		return fmt.Errorf("failed to read cache directory: %v", err)
+		return nil, fmt.Errorf("failed to read cache directory: %v", err)
 	}
 
-	now := time.Now()
+	var infos []*EntryInfo
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileExt) {
 			continue
 		}
 
-		// 加载缓存文件
 		filePath := filepath.Join(c.CacheDir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
 		data, err := os.ReadFile(filePath)
 		if err != nil {
 			logcdl.Warn("failed to read cache file %s: %v", filePath, err)
 			continue
 		}
 
-		var result types.QueryResult
-		if err := json.Unmarshal(data, &result); err != nil {
+		var header cacheHeader
+		if err := json.Unmarshal(data, &header); err != nil {
 			logcdl.Warn("failed to unmarshal cache file %s: %v", filePath, err)
 			continue
 		}
 
-		// 检查是否过期
-		if now.Sub(result.CreatedAt) > c.TTL {
-			// 删除内存缓存
-			queryID := strings.TrimSuffix(entry.Name(), ".json")
-			delete(c.Data, queryID)
-
-			// 删除文件缓存
-			if err := os.Remove(filePath); err != nil {
-				logcdl.Warn("failed to remove expired cache file %s: %v", filePath, err)
+		key := strings.TrimSuffix(entry.Name(), cacheFileExt)
+		size := info.Size()
+		for _, batchKey := range batchSetKeys(key, header.BatchKeys) {
+			if set, ok := c.batchSets[batchKey]; ok {
+				size += set.size
 			}
 		}
+
+		infos = append(infos, &EntryInfo{
+			Key:        key,
+			QueryID:    header.QueryID,
+			Query:      header.Query,
+			Status:     header.Status,
+			Progress:   header.Progress,
+			CreatedAt:  header.CreatedAt,
+			AccessedAt: info.ModTime(),
+			Size:       size,
+			Expired:    header.CacheVersion != FormatVersion || time.Since(header.CreatedAt) > c.TTL,
+		})
+	}
+
+	return infos, nil
+}
+
+// Load 实现 Inspector 接口
+func (c *FileCache) Load(key string) (*types.QueryResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.loadFromFile(key)
+}
+
+// Delete 实现 Inspector 接口
+func (c *FileCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(key)
+
+	return nil
+}
+
+// Clear 实现 Inspector 接口
+func (c *FileCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.CacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), cacheFileExt) {
+			c.remove(strings.TrimSuffix(entry.Name(), cacheFileExt))
+		}
+	}
+	for key := range c.batchSets {
+		c.forgetBatches(key)
+	}
+
+	return os.RemoveAll(filepath.Join(c.CacheDir, batchDirName))
+}
+
+// Close 实现 Cache 接口, 文件缓存没有需要释放的资源
+func (c *FileCache) Close() error {
+	return nil
+}
+
+// scan 扫描缓存目录, 按文件修改时间恢复缓存文件的访问顺序并统计缓存文件及批次缓存的总大小
+func (c *FileCache) scan() error {
+	entries, err := os.ReadDir(c.CacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %v", err)
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	// 从最久未访问的文件开始依次放到最前面
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, info := range infos {
+		// 缓存文件只包含 VariationID, 读取批次缓存键的开销较小
+		var header cacheHeader
+		if data, err := os.ReadFile(filepath.Join(c.CacheDir, info.Name())); err == nil {
+			if err := json.Unmarshal(data, &header); err != nil {
+				logcdl.Debug("failed to unmarshal cache file %s: %v", info.Name(), err)
+			}
+		}
+		c.track(strings.TrimSuffix(info.Name(), cacheFileExt), info.Size(), header.BatchKeys)
+	}
+
+	c.scanBatches()
+
+	return nil
+}
+
+// track 记录缓存文件的大小及批次缓存键并将其标记为最近访问, 调用前需要持有锁
+func (c *FileCache) track(queryID string, size int64, batchKeys []string) {
+	if elem, ok := c.files[queryID]; ok {
+		file := elem.Value.(*cacheFile)
+		c.diskBytes += size - file.size
+		file.size = size
+		file.batchKeys = batchKeys
+		c.fileLRU.MoveToFront(elem)
+		return
+	}
+
+	c.files[queryID] = c.fileLRU.PushFront(&cacheFile{key: queryID, size: size, batchKeys: batchKeys})
+	c.diskBytes += size
+}
+
+// touch 将缓存文件标记为最近访问, 并更新文件修改时间使访问顺序在下次运行时仍然有效, 调用前需要持有锁
+func (c *FileCache) touch(queryID string) {
+	elem, ok := c.files[queryID]
+	if !ok {
+		// 缓存文件由其他进程写入, 补充记录其大小
+		info, err := os.Stat(c.filePath(queryID))
+		if err != nil {
+			return
+		}
+		c.track(queryID, info.Size(), nil)
+		c.evict(queryID)
+		return
+	}
+
+	c.fileLRU.MoveToFront(elem)
+	now := time.Now()
+	if err := os.Chtimes(c.filePath(queryID), now, now); err != nil {
+		logcdl.Debug("failed to update access time of cache file for query '%v': %v", queryID, err)
+	}
+}
+
+// remember 将缓存放入内存, 超过 maxEntries 时只从内存中移除最久未访问的缓存, 调用前需要持有锁
+func (c *FileCache) remember(queryID string, entry *types.QueryResult) {
+	if elem, ok := c.memory[queryID]; ok {
+		elem.Value.(*memoryEntry).entry = entry
+		c.memoryLRU.MoveToFront(elem)
+	} else {
+		c.memory[queryID] = c.memoryLRU.PushFront(&memoryEntry{key: queryID, entry: entry})
+	}
+
+	for c.memoryLRU.Len() > c.maxEntries {
+		oldest := c.memoryLRU.Back()
+		c.memoryLRU.Remove(oldest)
+		delete(c.memory, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// evict 缓存文件及批次缓存的总大小超过 MaxSize 时删除缓存, 调用前需要持有锁
+// 先从最早创建的开始删除不属于任何缓存且本次运行中未读写过的批次, 仍然超过时再删除最久未访问的缓存及其批次
+// keep 为刚写入的缓存或批次缓存的键, 不会被删除
+func (c *FileCache) evict(keep string) {
+	if c.MaxSize <= 0 || c.diskBytes <= c.MaxSize {
+		return
+	}
+
+	owned := make(map[string]bool, len(c.files))
+	for _, elem := range c.files {
+		file := elem.Value.(*cacheFile)
+		for _, key := range batchSetKeys(file.key, file.batchKeys) {
+			owned[key] = true
+		}
+	}
+
+	var stale []string
+	for key := range c.batchSets {
+		if !owned[key] && !c.live.has(key) {
+			stale = append(stale, key)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return c.batchSets[stale[i]].createdAt.Before(c.batchSets[stale[j]].createdAt)
+	})
+
+	var evictedBatches int
+	for _, key := range stale {
+		if c.diskBytes <= c.MaxSize {
+			break
+		}
+		c.removeBatches(key)
+		evictedBatches++
+	}
+
+	var evicted int
+	for elem := c.fileLRU.Back(); elem != nil && c.diskBytes > c.MaxSize; {
+		prev := elem.Prev()
+		if key := elem.Value.(*cacheFile).key; key != keep {
+			c.remove(key)
+			evicted++
+		}
+		elem = prev
+	}
+
+	if c.diskBytes > c.MaxSize {
+		// 单条缓存超过最大大小时保留该缓存, 其余缓存均已删除
+		logcdl.Warn("cache entry for query '%v' alone exceeds the cache max size of %d MB", keep, c.MaxSize>>20)
+	}
+	if evictedBatches > 0 {
+		logcdl.Info("evicted cached batches of %d unfinished or replaced queries to stay under %d MB (%.1f MB in use)",
+			evictedBatches, c.MaxSize>>20, float64(c.diskBytes)/(1<<20))
+	}
+	if evicted > 0 {
+		logcdl.Info("evicted %d least recently used cache files to stay under %d MB (%.1f MB in use)",
+			evicted, c.MaxSize>>20, float64(c.diskBytes)/(1<<20))
+	}
+}
+
+// remove 从内存及磁盘中删除缓存及其批次缓存, 调用前需要持有锁
+func (c *FileCache) remove(queryID string) {
+	if elem, ok := c.memory[queryID]; ok {
+		c.memoryLRU.Remove(elem)
+		delete(c.memory, queryID)
+	}
+
+	batchKeys := []string{queryID}
+	if elem, ok := c.files[queryID]; ok {
+		file := elem.Value.(*cacheFile)
+		batchKeys = batchSetKeys(queryID, file.batchKeys)
+		c.diskBytes -= file.size
+		c.fileLRU.Remove(elem)
+		delete(c.files, queryID)
+	}
+
+	if err := os.Remove(c.filePath(queryID)); err != nil && !os.IsNotExist(err) {
+		logcdl.Warn("failed to remove cache file for query '%v': %v", queryID, err)
+	}
+
+	// 查询结果的文档摘要保存在批次缓存中, 一并删除
+	for _, key := range batchKeys {
+		c.removeBatches(key)
+	}
+}
+
+// filePath 返回缓存文件路径
+func (c *FileCache) filePath(queryID string) string {
+	return filepath.Join(c.CacheDir, queryID+cacheFileExt)
+}
+
+// loadFromFile 从文件加载缓存
+func (c *FileCache) loadFromFile(queryID string) (*types.QueryResult, error) {
+	data, err := os.ReadFile(c.filePath(queryID))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry types.QueryResult
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	// 检查 Result  是否为 nil
+	if entry.Result == nil {
+		logcdl.Warn("invalid cache data for query '%v': nil result", queryID)
+		return nil, fmt.Errorf("invalid cache data: nil result")
+	}
+
+	return &entry, nil
+}
+
+// saveToFile 保存缓存到文件, 返回文件大小
+func (c *FileCache) saveToFile(queryID string, entry *types.QueryResult) (int64, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writeFileAtomic(c.filePath(queryID), data); err != nil {
+		return 0, err
+	}
+
+	return int64(len(data)), nil
+}
+
+// writeFileAtomic 先写入临时文件再重命名, 写入中断时不会留下不完整的文件
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
 	}
 
 	return nil
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// FormatVersion 缓存格式版本
+// 缓存内容或缓存键的计算方式发生变化时递增, 版本不同的缓存视为无效并被删除
+const FormatVersion = 3
+
+// Fingerprint 定义决定查询结果的全部请求参数, 所有参数相同的查询才能共用缓存
+// 补充阶段会在缓存的文档摘要中附加信息, 因此也属于指纹的一部分
+type Fingerprint struct {
+	Version    int      `json:"version"`
+	DB         string   `json:"db"`
+	Content    string   `json:"content"`
+	SearchType string   `json:"search_type,omitempty"`
+	Filters    string   `json:"filters,omitempty"`
+	DateType   string   `json:"date_type,omitempty"`
+	MinDate    string   `json:"min_date,omitempty"`
+	MaxDate    string   `json:"max_date,omitempty"`
+	RelDate    int      `json:"rel_date,omitempty"`
+	Sort       string   `json:"sort,omitempty"`
+	FetchVCV   bool     `json:"fetch_vcv,omitempty"`
+	Links      []string `json:"links,omitempty"`
+}
+
+// NewFingerprint 根据查询及当前配置创建缓存指纹, 不影响结果的写法差异 (如空白、links 的顺序) 不改变指纹
+func NewFingerprint(query *types.Query, cfg *config.Config) *Fingerprint {
+	params := cfg.EntrezParams
+	f := &Fingerprint{
+		Version:    FormatVersion,
+		DB:         params.DB,
+		Content:    strings.TrimSpace(query.Content),
+		SearchType: query.SearchType,
+		Filters:    strings.Join(strings.Fields(params.Filters), " "),
+		Sort:       params.Sort,
+	}
+
+	// 未设置日期范围时日期类型不影响结果
+	if params.HasDateRange() {
+		f.DateType = params.DateType
+		f.MinDate = params.MinDate
+		f.MaxDate = params.MaxDate
+		f.RelDate = params.RelDate
+	}
+
+	if cfg.Enrichment != nil {
+		f.FetchVCV = cfg.Enrichment.FetchVCV
+		if len(cfg.Enrichment.Links) > 0 {
+			f.Links = append([]string(nil), cfg.Enrichment.Links...)
+			sort.Strings(f.Links)
+		}
+	}
+
+	return f
+}
+
+// String 返回指纹的规范化 JSON 表示, 字段顺序固定
+func (f *Fingerprint) String() string {
+	data, _ := json.Marshal(f)
+	return string(data)
+}
+
+// Key 返回查询对应的缓存键
+func (f *Fingerprint) Key(query *types.Query) string {
+	return query.GetCacheKey(f.String())
+}
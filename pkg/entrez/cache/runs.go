@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	runStatsFileName = "run_stats.jsonl" // 最近几次运行的缓存命中情况, 每行一次运行
+	maxRecordedRuns  = 20                // 最多保留的运行记录数
+)
+
+// RunStats 定义单次运行中查询缓存的命中情况
+type RunStats struct {
+	StartedAt time.Time `json:"started_at"`
+	Queries   int       `json:"queries"` // 查询总数
+	Hits      int       `json:"hits"`    // 命中完整缓存的查询数
+	Resumed   int       `json:"resumed"` // 缓存不完整, 从批次缓存继续执行的查询数
+	Misses    int       `json:"misses"`  // 未命中缓存的查询数
+}
+
+// HitRatio 返回命中完整缓存的比例
+func (r *RunStats) HitRatio() float64 {
+	lookups := r.Hits + r.Resumed + r.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(lookups)
+}
+
+// RecordRun 在缓存目录中记录一次运行的缓存命中情况, 只保留最近的 maxRecordedRuns 次
+func RecordRun(cacheDir string, run *RunStats) error {
+	runs, err := LoadRuns(cacheDir)
+	if err != nil {
+		return err
+	}
+
+	runs = append(runs, run)
+	if len(runs) > maxRecordedRuns {
+		runs = runs[len(runs)-maxRecordedRuns:]
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, r := range runs {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(filepath.Join(cacheDir, runStatsFileName), buf.Bytes())
+}
+
+// LoadRuns 返回缓存目录中记录的最近几次运行, 按运行时间先后排列
+func LoadRuns(cacheDir string) ([]*RunStats, error) {
+	file, err := os.Open(filepath.Join(cacheDir, runStatsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var runs []*RunStats
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var run RunStats
+		// 跳过损坏的记录
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			continue
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, scanner.Err()
+}
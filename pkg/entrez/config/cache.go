@@ -10,19 +10,46 @@ import (
 
 // CacheConfig 定义缓存配置
 type CacheConfig struct {
-	Enabled bool          // 是否启用缓存
-	Dir     string        // 缓存目录
-	TTL     time.Duration // 缓存过期时间
-	MaxSize int64         // 缓存最大大小（字节）
+	Enabled    bool          // 是否启用缓存
+	Dir        string        // 缓存目录
+	TTL        time.Duration // 缓存过期时间
+	MaxSize    int64         // 缓存最大大小（字节）, 超过时删除最久未访问的缓存文件
+	MaxEntries int           // 内存中最多保留的缓存条目数
+	Backend    CacheBackend  // 缓存后端
 }
 
+const (
+	CacheBackendFile CacheBackend = "file" // 每个查询一个 JSON 文件
+	CacheBackendBolt CacheBackend = "bolt" // 单个 bbolt 数据库文件, 压缩保存并按 VariationID 建立索引
+)
+
+// CacheBackend 定义缓存的存储方式
+type CacheBackend string
+
+// IsValid 检查缓存后端是否有效
+func (b CacheBackend) IsValid() bool {
+	switch b {
+	case CacheBackendFile, CacheBackendBolt:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	DefaultCacheMaxEntries = 32   // 内存中默认最多保留的缓存条目数
+	MaxCacheMaxEntries     = 1000 // 内存中最多保留的缓存条目数上限
+)
+
 // NewCacheConfig 创建一个新的缓存配置
 func NewCacheConfig(enabled bool, dir string, ttl time.Duration, maxSize int64) *CacheConfig {
 	return &CacheConfig{
-		Enabled: enabled,
-		Dir:     dir,
-		TTL:     ttl,
-		MaxSize: maxSize,
+		Enabled:    enabled,
+		Dir:        dir,
+		TTL:        ttl,
+		MaxSize:    maxSize,
+		MaxEntries: DefaultCacheMaxEntries,
+		Backend:    CacheBackendFile,
 	}
 }
 
@@ -61,6 +88,16 @@ func (c *CacheConfig) validateCache() error {
 			minSize>>20, maxSize>>20))
 	}
 
+	// 验证缓存后端
+	if !c.Backend.IsValid() {
+		return customerrors.NewParametersError(fmt.Sprintf("cache backend %s is not supported, use %s or %s", c.Backend, CacheBackendFile, CacheBackendBolt))
+	}
+
+	// 验证内存中的缓存条目数
+	if c.MaxEntries < 1 || c.MaxEntries > MaxCacheMaxEntries {
+		return customerrors.NewParametersError(fmt.Sprintf("cache max entries must be between 1 and %d", MaxCacheMaxEntries))
+	}
+
 	return nil
 }
 
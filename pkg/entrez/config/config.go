@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/http"
 	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"strings"
 	"time"
 )
 
@@ -25,6 +26,9 @@ type Config struct {
 
 	// HTTP 客户端配置
 	HTTP *http.HTTPClientConfig
+
+	// 补充阶段配置
+	Enrichment *EnrichmentConfig
 }
 
 // NewConfig 创建一个新的配置实例
@@ -33,12 +37,50 @@ func NewConfig(db string) *Config {
 		EntrezParams: &EntrezParams{
 			DB: db,
 		},
-		Runtime: newRuntimeConfig(false),
-		Cache:   NewCacheConfig(false, "", 0, 0),
-		Stream:  DefaultStreamConfig(),
-		Output:  NewOutputConfig(""),
-		HTTP:    http.DefaultHTTPConfig(),
+		Runtime:    newRuntimeConfig(false),
+		Cache:      NewCacheConfig(false, "", 0, 0),
+		Stream:     DefaultStreamConfig(),
+		Output:     NewOutputConfig(""),
+		HTTP:       http.DefaultHTTPConfig(),
+		Enrichment: NewEnrichmentConfig(),
+	}
+}
+
+// Clone 复制配置, 用于多任务运行时为每个任务设置不同的参数
+func (c *Config) Clone() *Config {
+	clone := *c
+
+	if c.EntrezParams != nil {
+		params := *c.EntrezParams
+		clone.EntrezParams = &params
+	}
+	if c.Runtime != nil {
+		runtime := *c.Runtime
+		clone.Runtime = &runtime
+	}
+	if c.Cache != nil {
+		cache := *c.Cache
+		clone.Cache = &cache
 	}
+	if c.Stream != nil {
+		stream := *c.Stream
+		clone.Stream = &stream
+	}
+	if c.Output != nil {
+		output := *c.Output
+		clone.Output = &output
+	}
+	if c.HTTP != nil {
+		httpConfig := *c.HTTP
+		clone.HTTP = &httpConfig
+	}
+	if c.Enrichment != nil {
+		enrichment := *c.Enrichment
+		enrichment.Links = append([]string(nil), c.Enrichment.Links...)
+		clone.Enrichment = &enrichment
+	}
+
+	return &clone
 }
 
 func (c *Config) SetFilters(filters string) *Config {
@@ -71,6 +113,37 @@ func (c *Config) SetApiKey(apiKey string) *Config {
 	return c
 }
 
+// SetDateRange 设置 esearch 的日期范围
+// 只设置 minDate 时 maxDate 默认为当天, 未设置 dateType 时默认使用修改日期
+func (c *Config) SetDateRange(dateType, minDate, maxDate string) *Config {
+	c.EntrezParams.DateType = NormalizeDateType(dateType)
+	c.EntrezParams.MinDate = NormalizeEntrezDate(minDate)
+	c.EntrezParams.MaxDate = NormalizeEntrezDate(maxDate)
+
+	if c.EntrezParams.MinDate != "" && c.EntrezParams.MaxDate == "" {
+		c.EntrezParams.MaxDate = time.Now().Format("2006/01/02")
+	}
+	if c.EntrezParams.HasDateRange() && c.EntrezParams.DateType == "" {
+		c.EntrezParams.DateType = DefaultDateType
+	}
+	return c
+}
+
+// SetRelDate 设置 esearch 只返回最近 days 天内的记录
+func (c *Config) SetRelDate(days int) *Config {
+	c.EntrezParams.RelDate = days
+	if days > 0 && c.EntrezParams.DateType == "" {
+		c.EntrezParams.DateType = DefaultDateType
+	}
+	return c
+}
+
+// SetSort 设置 esearch 结果的排序方式
+func (c *Config) SetSort(sort string) *Config {
+	c.EntrezParams.Sort = strings.TrimSpace(sort)
+	return c
+}
+
 func (c *Config) SetEmail(email string) *Config {
 	c.EntrezParams.Email = email
 	return c
@@ -122,6 +195,26 @@ func (c *Config) SetCacheMaxSize(size int64) *Config {
 	return c
 }
 
+// SetCacheMaxEntries 设置内存中最多保留的缓存条目数, 小于等于 0 时使用默认值
+func (c *Config) SetCacheMaxEntries(entries int) *Config {
+	if c.Cache == nil || entries <= 0 {
+		return c
+	}
+
+	c.Cache.MaxEntries = entries
+	return c
+}
+
+// SetCacheBackend 设置缓存后端, 为空时使用默认的文件缓存
+func (c *Config) SetCacheBackend(backend CacheBackend) *Config {
+	if c.Cache == nil || backend == "" {
+		return c
+	}
+
+	c.Cache.Backend = backend
+	return c
+}
+
 // SetStreamEnabled 设置是否启用流式处理
 func (c *Config) SetStreamEnabled(enabled bool) *Config {
 	if c.Stream == nil {
@@ -143,6 +236,20 @@ func (c *Config) SetQueryTimeout(timeout time.Duration) *Config {
 	return c
 }
 
+// SetMaxQueryRecords 设置单个查询的最大记录数, 超过时拆分查询, 0 表示不拆分
+// 运行时配置会在 SetApiKey 时重建, 需在 SetApiKey 之后调用
+func (c *Config) SetMaxQueryRecords(records int) *Config {
+	c.Runtime.MaxQueryRecords = records
+	return c
+}
+
+// SetReconcile 设置是否在记录缺失时按 UID 核对并补齐
+// 运行时配置会在 SetApiKey 时重建, 需在 SetApiKey 之后调用
+func (c *Config) SetReconcile(enabled bool) *Config {
+	c.Runtime.Reconcile = enabled
+	return c
+}
+
 // SetSingleQueryTimeout 设置单个查询超时时间
 func (c *Config) SetSingleQueryTimeout(timeout time.Duration) *Config {
 	c.Runtime.SingleQueryTimeout = timeout
@@ -155,6 +262,34 @@ func (c *Config) SetWriteTimeout(timeout time.Duration) *Config {
 	return c
 }
 
+// SetFetchVCV 设置是否通过 efetch 获取完整 VCV 记录
+func (c *Config) SetFetchVCV(enabled bool) *Config {
+	if c.Enrichment == nil {
+		c.Enrichment = NewEnrichmentConfig()
+	}
+	c.Enrichment.FetchVCV = enabled
+	return c
+}
+
+// SetLinks 设置通过 elink 获取关联记录的目标数据库, 忽略大小写及重复项
+func (c *Config) SetLinks(links []string) *Config {
+	if c.Enrichment == nil {
+		c.Enrichment = NewEnrichmentConfig()
+	}
+
+	c.Enrichment.Links = nil
+	seen := make(map[string]struct{})
+	for _, link := range links {
+		link = strings.ToLower(strings.TrimSpace(link))
+		if _, ok := seen[link]; ok || link == "" {
+			continue
+		}
+		seen[link] = struct{}{}
+		c.Enrichment.Links = append(c.Enrichment.Links, link)
+	}
+	return c
+}
+
 // Validate 验证配置是否有效
 func (c *Config) Validate() error {
 	// 基本验证
@@ -191,5 +326,12 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	// 验证补充阶段配置
+	if c.Enrichment != nil {
+		if err := c.Enrichment.validateEnrichment(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
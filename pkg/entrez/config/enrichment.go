@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+const (
+	DefaultFetchBatchSize = 50  // 完整 VCV 记录体积较大, 单次 efetch 请求的记录数远小于 esummary
+	MaxFetchBatchSize     = 200 // 单次 efetch 请求的最大记录数
+	DefaultLinkBatchSize  = 100 // 单次 elink 请求的 UID 数
+	MaxLinkBatchSize      = 500 // 单次 elink 请求的最大 UID 数
+)
+
+// supportedLinks 支持的 elink 目标数据库
+var supportedLinks = map[string]struct{}{
+	types.LinkPubMed: {},
+	types.LinkMedGen: {},
+	types.LinkGene:   {},
+}
+
+// EnrichmentConfig 定义 esummary 之后的可选补充阶段配置
+type EnrichmentConfig struct {
+	FetchVCV       bool     // 是否通过 efetch 获取完整 VCV 记录
+	FetchBatchSize int      // 单次 efetch 请求的记录数
+	Links          []string // 通过 elink 获取关联记录的目标数据库: pubmed、medgen、gene
+	LinkBatchSize  int      // 单次 elink 请求的 UID 数
+}
+
+// NewEnrichmentConfig 创建默认的补充阶段配置
+func NewEnrichmentConfig() *EnrichmentConfig {
+	return &EnrichmentConfig{
+		FetchVCV:       false,
+		FetchBatchSize: DefaultFetchBatchSize,
+		LinkBatchSize:  DefaultLinkBatchSize,
+	}
+}
+
+// validateEnrichment 验证补充阶段配置
+func (c *EnrichmentConfig) validateEnrichment() error {
+	if c.FetchVCV && (c.FetchBatchSize <= 0 || c.FetchBatchSize > MaxFetchBatchSize) {
+		return customerrors.NewParametersError(fmt.Sprintf("efetch batch size must be between 1 and %d", MaxFetchBatchSize))
+	}
+
+	for _, link := range c.Links {
+		if _, ok := supportedLinks[link]; !ok {
+			return customerrors.NewParametersError(fmt.Sprintf("unsupported link database '%s', supported: %s, %s, %s",
+				link, types.LinkPubMed, types.LinkMedGen, types.LinkGene))
+		}
+	}
+
+	if len(c.Links) > 0 && (c.LinkBatchSize <= 0 || c.LinkBatchSize > MaxLinkBatchSize) {
+		return customerrors.NewParametersError(fmt.Sprintf("elink batch size must be between 1 and %d", MaxLinkBatchSize))
+	}
+
+	return nil
+}
@@ -4,8 +4,32 @@ import (
 	"fmt"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
 	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 )
 
+// DefaultDateType 设置了日期范围但未指定 datetype 时使用的日期类型 (记录修改日期)
+const DefaultDateType = "mdat"
+
+// entrezDateTypes esearch 在 ClinVar 中支持的日期类型
+// ClinVar 没有按最后评估日期 (last evaluated) 筛选的日期类型, esearch 会忽略不支持的 datetype 并返回全部记录
+var entrezDateTypes = []string{"mdat", "pdat", "edat"}
+
+// dateTypeAliases 日期类型的别名
+var dateTypeAliases = map[string]string{
+	"modification": "mdat",
+	"modified":     "mdat",
+	"publication":  "pdat",
+	"published":    "pdat",
+	"entrez":       "edat",
+	"created":      "edat",
+}
+
+// entrezDatePattern 匹配 esearch 支持的日期格式: YYYY、YYYY/MM、YYYY/MM/DD
+var entrezDatePattern = regexp.MustCompile(`^\d{4}(/\d{2}(/\d{2})?)?$`)
+
 // EntrezParams 包含所有 Entrez API 调用相关的基本参数
 type EntrezParams struct {
 	DB         string
@@ -16,6 +40,30 @@ type EntrezParams struct {
 	ApiKey     string
 	Email      string
 	ToolName   string
+	DateType   string // 日期类型, 如: mdat (修改日期)、pdat (发布日期)、edat (收录日期)
+	MinDate    string // 起始日期, 格式: YYYY、YYYY/MM 或 YYYY/MM/DD
+	MaxDate    string // 结束日期, 格式同 MinDate
+	RelDate    int    // 最近 n 天, 与 MinDate/MaxDate 互斥
+	Sort       string // esearch 结果的排序方式
+}
+
+// NormalizeDateType 将日期类型的别名转换为 esearch 使用的缩写
+func NormalizeDateType(dateType string) string {
+	dateType = strings.ToLower(strings.TrimSpace(dateType))
+	if alias, ok := dateTypeAliases[dateType]; ok {
+		return alias
+	}
+	return dateType
+}
+
+// NormalizeEntrezDate 将 2024-01-02 等格式的日期统一为 esearch 使用的 2024/01/02
+func NormalizeEntrezDate(date string) string {
+	return strings.ReplaceAll(strings.TrimSpace(date), "-", "/")
+}
+
+// HasDateRange 是否设置了日期范围
+func (e *EntrezParams) HasDateRange() bool {
+	return e.MinDate != "" || e.MaxDate != "" || e.RelDate > 0
 }
 
 // validateRequired 验证必需参数
@@ -35,6 +83,60 @@ func (e *EntrezParams) validateRequired() error {
 		return customerrors.NewParametersError(fmt.Sprintf("retmax %d exceeds maximum allowed value (10000)", e.RetMax))
 	}
 
+	// 验证日期范围
+	if err := e.validateDates(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateDates 验证日期范围参数
+func (e *EntrezParams) validateDates() error {
+	if e.RelDate < 0 {
+		return customerrors.NewParametersError(fmt.Sprintf("reldate %d must be a positive number of days", e.RelDate))
+	}
+
+	if e.RelDate > 0 && (e.MinDate != "" || e.MaxDate != "") {
+		return customerrors.NewParametersError("reldate cannot be used together with mindate or maxdate")
+	}
+
+	// esearch 要求 mindate 和 maxdate 成对出现
+	if e.MaxDate != "" && e.MinDate == "" {
+		return customerrors.NewParametersError("maxdate requires mindate")
+	}
+
+	var minDate, maxDate time.Time
+	for _, d := range []struct {
+		name  string
+		value string
+		time  *time.Time
+	}{{"mindate", e.MinDate, &minDate}, {"maxdate", e.MaxDate, &maxDate}} {
+		if d.value == "" {
+			continue
+		}
+		if !entrezDatePattern.MatchString(d.value) {
+			return customerrors.NewParametersError(fmt.Sprintf("invalid %s '%s', expected YYYY, YYYY/MM or YYYY/MM/DD", d.name, d.value))
+		}
+		parsed, err := time.Parse("2006/01/02", (d.value + "/01/01")[:10])
+		if err != nil {
+			return customerrors.NewParametersError(fmt.Sprintf("invalid %s '%s': %v", d.name, d.value, err))
+		}
+		*d.time = parsed
+	}
+
+	if !minDate.IsZero() && !maxDate.IsZero() && minDate.After(maxDate) {
+		return customerrors.NewParametersError(fmt.Sprintf("mindate %s is after maxdate %s", e.MinDate, e.MaxDate))
+	}
+
+	if e.HasDateRange() && e.DateType == "" {
+		return customerrors.NewParametersError("datetype is required when a date range is set")
+	}
+
+	if e.DateType != "" && !slices.Contains(entrezDateTypes, e.DateType) {
+		return customerrors.NewParametersError(fmt.Sprintf("datetype '%s' is not supported, use one of: mdat (modification), pdat (publication), edat (entrez)", e.DateType))
+	}
+
 	return nil
 }
 
@@ -21,6 +21,9 @@ const (
 	DefaultMinTimeout = 10 * time.Second
 	DefaultMaxTimeout = 120 * time.Minute
 
+	// 单个查询的最大记录数, 超过时拆分查询, 0 表示不拆分
+	DefaultMaxQueryRecords = 20000
+
 	// 默认最大响应大小
 	DefaultMinResponseSize = 10 << 20  // 10MB
 	DefaultMaxResponseSize = 100 << 20 // 100MB
@@ -80,6 +83,14 @@ type runtimeConfig struct {
 	// 响应大小配置
 	MaxResponseSize int64 // 最大响应大小
 
+	// 查询拆分配置
+	// 合并了多个检索词的查询结果过多时, 所有批次共用一个 WebEnv, 容易因 WebEnv 过期或超时而全部失败
+	MaxQueryRecords int // esearch 结果超过该值时将查询拆分为子查询, 0 表示不拆分
+
+	// 记录核对配置
+	// 批次返回的记录数少于预期时, 获取完整的 UID 列表并通过 UID 补齐缺失的记录
+	Reconcile bool
+
 	// 超时配置
 	QueryTimeout       time.Duration // 总查询超时时间
 	SingleQueryTimeout time.Duration // 单个查询超时时间
@@ -105,6 +116,7 @@ func newRuntimeConfig(hasAPIKey bool) *runtimeConfig {
 		MinBatchSize:       DefaultMinBatchSize,
 		MaxBatchSize:       DefaultMaxBatchSize,
 		MaxResponseSize:    DefaultMaxResponseSize,
+		MaxQueryRecords:    DefaultMaxQueryRecords,
 		QueryTimeout:       30 * time.Minute,
 		SingleQueryTimeout: 20 * time.Minute,
 		WriteTimeout:       10 * time.Minute,
@@ -124,6 +136,7 @@ func (r *runtimeConfig) validate(hasAPIKey bool) error {
 		r.validateBatchSize,
 		r.validateTimeout,
 		r.validateResponseSize,
+		r.validateMaxQueryRecords,
 		func() error { return r.validateWorkers(hasAPIKey) },
 	} {
 		if err := fn(); err != nil {
@@ -177,6 +190,17 @@ func (r *runtimeConfig) validateResponseSize() error {
 	return nil
 }
 
+// validateMaxQueryRecords 验证单个查询的最大记录数
+func (r *runtimeConfig) validateMaxQueryRecords() error {
+	if r.MaxQueryRecords < 0 {
+		return customerrors.NewParametersError("max query records must not be negative")
+	}
+	if r.MaxQueryRecords > 0 && r.MaxQueryRecords < r.BatchSize {
+		return customerrors.NewParametersError(fmt.Sprintf("max query records must be 0 (disabled) or at least the batch size %d", r.BatchSize))
+	}
+	return nil
+}
+
 // validateWorkers 验证并发数
 func (r *runtimeConfig) validateWorkers(hasAPIKey bool) error {
 	if !hasAPIKey {
@@ -0,0 +1,126 @@
+package entrez
+
+import (
+	"context"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// deduplicator 按 VariationID (DocumentSummary.Uid) 对所有查询的结果去重
+// 多个基因合并为一个查询时, 跨基因的变异会出现在多个查询的结果中; 同一变异只写入首次出现的记录
+// 结果流式写入, 每个变异只保留首次命中的查询序号, 只有出现在多个查询中的变异才保留完整的来源
+type deduplicator struct {
+	sources    []*types.QueryResult            // 来源查询, 只包含查询 ID、内容、区间及基因名映射
+	sourceIDs  map[string]int                  // 查询 ID -> sources 中的序号
+	first      map[string]int                  // 已写入的变异 -> 首次命中的查询序号
+	shared     map[string]*types.SharedVariant // 出现在多个查询中的变异及其所有来源
+	order      []string                        // 出现在多个查询中的变异, 按首次重复出现的顺序
+	duplicates int                             // 不同查询之间的重复记录数
+}
+
+func newDeduplicator() *deduplicator {
+	return &deduplicator{
+		sourceIDs: make(map[string]int),
+		first:     make(map[string]int),
+		shared:    make(map[string]*types.SharedVariant),
+	}
+}
+
+// run 从 in 读取结果, 移除重复记录后发送到 out, in 关闭或 ctx 取消后返回
+// 结果可能与缓存共用, 去重时创建新的结果及文档摘要副本, 不修改原有结果
+func (d *deduplicator) run(ctx context.Context, in <-chan *types.QueryResult, out chan<- *types.QueryResult) {
+	for result := range in {
+		if result == nil || result.Result == nil {
+			if !sendResult(ctx, out, result) {
+				return
+			}
+			continue
+		}
+
+		source := d.source(result)
+		docs := make([]*types.DocumentSummary, 0, len(result.Result.DocumentSummarySet.DocumentSummary))
+		for _, doc := range result.Result.DocumentSummarySet.DocumentSummary {
+			if first, ok := d.first[doc.Uid]; ok {
+				d.merge(first, result, doc)
+				continue
+			}
+
+			d.first[doc.Uid] = source
+			unique := *doc
+			unique.Origin = types.NewVariantOrigin(result, doc)
+			docs = append(docs, &unique)
+		}
+
+		deduplicated := *result.Result
+		deduplicated.DocumentSummarySet.DocumentSummary = docs
+		if !sendResult(ctx, out, &types.QueryResult{
+			QueryID:         result.QueryID,
+			Query:           result.Query,
+			Result:          &deduplicated,
+			Regions:         result.Regions,
+			ResolvedSymbols: result.ResolvedSymbols,
+		}) {
+			// 写入端已停止接收, 不再转发之后的结果
+			logcdl.Warn("context cancelled while forwarding deduplicated results: %v", ctx.Err())
+			return
+		}
+	}
+
+	if d.duplicates > 0 {
+		logcdl.Info("merged %d duplicate records of %d variants found by multiple queries", d.duplicates, len(d.order))
+	}
+}
+
+// source 返回结果所属查询在 sources 中的序号, 首次出现的查询只记录查询信息, 不记录结果
+func (d *deduplicator) source(result *types.QueryResult) int {
+	if index, ok := d.sourceIDs[result.QueryID]; ok {
+		return index
+	}
+
+	d.sources = append(d.sources, &types.QueryResult{
+		QueryID:         result.QueryID,
+		Query:           result.Query,
+		Regions:         result.Regions,
+		ResolvedSymbols: result.ResolvedSymbols,
+	})
+	d.sourceIDs[result.QueryID] = len(d.sources) - 1
+	return len(d.sources) - 1
+}
+
+// merge 将重复出现的变异的来源查询合并到已写入的记录中
+// 只统计不同查询之间的重复记录: 同一查询的拆分子查询重叠或重新发送的批次在各查询的已处理记录数中已去重, 不计入
+func (d *deduplicator) merge(first int, result *types.QueryResult, doc *types.DocumentSummary) {
+	variant, ok := d.shared[doc.Uid]
+	if !ok {
+		if d.sources[first].QueryID == result.QueryID {
+			return
+		}
+		variant = types.NewSharedVariant(d.sources[first], doc)
+		d.shared[doc.Uid] = variant
+		d.order = append(d.order, doc.Uid)
+	}
+
+	if variant.Origin.Merge(result, doc) {
+		d.duplicates++
+	}
+}
+
+// sendResult 发送结果, ctx 取消时返回 false
+func sendResult(ctx context.Context, out chan<- *types.QueryResult, result *types.QueryResult) bool {
+	select {
+	case out <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sharedVariants 返回出现在多个查询中的变异及其所有来源, 只能在 run 返回后调用
+func (d *deduplicator) sharedVariants() []*types.SharedVariant {
+	variants := make([]*types.SharedVariant, 0, len(d.order))
+	for _, uid := range d.order {
+		variants = append(variants, d.shared[uid])
+	}
+	return variants
+}
@@ -0,0 +1,91 @@
+package entrez
+
+import (
+	"context"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/cache"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/output"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/pkg/errors"
+)
+
+// ExportResults 使用结果写入器保存缓存中的查询结果, 不发起网络请求
+// 缓存的查询结果只保留 VariationID, 文档摘要按批次从批次缓存中读取, 与 run 相同经过去重后流式写入
+func ExportResults(ctx context.Context, results []*types.QueryResult, batches cache.BatchCache, outputFile string, resultWriter output.Writer) error {
+	if resultWriter == nil {
+		return customerrors.NewEmptyResultError("result writer is nil")
+	}
+
+	if err := resultWriter.SetHeaders(nil); err != nil {
+		return errors.Wrapf(customerrors.ErrSaveResult, "failed to set headers: %v", err)
+	}
+
+	writeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 拆分查询的子查询批次可能重叠, 多个查询也可能包含同一变异, 按 VariationID 去重
+	batchChan := make(chan *types.QueryResult)
+	var readErr error
+	go func() {
+		defer close(batchChan)
+		readErr = readBatches(writeCtx, results, batches, batchChan)
+	}()
+
+	dedup := newDeduplicator()
+	resultChan := make(chan *types.QueryResult)
+	go func() {
+		defer close(resultChan)
+		dedup.run(writeCtx, batchChan, resultChan)
+		for range batchChan {
+			// 去重提前结束时等待读取结束
+		}
+	}()
+
+	writeErr := resultWriter.WriteResultStream(writeCtx, resultChan)
+	cancel()
+	for range resultChan {
+		// 写入出错时等待读取结束
+	}
+	if writeErr != nil {
+		return errors.Wrapf(customerrors.ErrSaveResult, "failed to write results: %v", writeErr)
+	}
+	if readErr != nil {
+		return errors.Wrapf(customerrors.ErrSaveResult, "failed to read cached batches: %v", readErr)
+	}
+
+	// 将出现在多个查询中的变异的所有来源写回该变异所在的行
+	if originWriter, ok := resultWriter.(output.OriginWriter); ok {
+		if err := originWriter.UpdateOrigins(dedup.sharedVariants()); err != nil {
+			return errors.Wrapf(customerrors.ErrSaveResult, "failed to update variant origins: %v", err)
+		}
+	}
+
+	if err := resultWriter.Save(outputFile); err != nil {
+		return errors.Wrapf(customerrors.ErrSaveResult, "failed to save results: %v", err)
+	}
+
+	return nil
+}
+
+// readBatches 按批次读取查询结果的文档摘要并发送到 out
+func readBatches(ctx context.Context, results []*types.QueryResult, batches cache.BatchCache, out chan<- *types.QueryResult) error {
+	for _, result := range results {
+		for _, key := range result.BatchKeys {
+			err := batches.EachBatch(key, func(batch *types.ESummaryResult) error {
+				select {
+				case out <- &types.QueryResult{QueryID: result.QueryID, Query: result.Query, Result: batch, Regions: result.Regions, ResolvedSymbols: result.ResolvedSymbols}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BEDParser 实现 BED 格式的区间文件解析器
+// BED 坐标为 0-based 半开区间, 解析时会转换为 clinvar 使用的 1-based 闭区间
+type BEDParser struct {
+	base *FileParser
+}
+
+// NewBEDParser 创建新的 BED 文件解析器, 无论配置的查询类型为何都按区间检索
+func NewBEDParser(base *FileParser) *BEDParser {
+	regionParser := *base
+	regionParser.flag = types.FlagRegion
+
+	return &BEDParser{
+		base: &regionParser,
+	}
+}
+
+// Parse 从 reader 解析查询
+func (p *BEDParser) Parse(reader io.Reader) ([]*types.Query, error) {
+	scanner := bufio.NewScanner(reader)
+	var items []item
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// 跳过空行、注释及 browser/track 头信息
+		if line == "" || strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, "track") || strings.HasPrefix(line, "browser") {
+			continue
+		}
+
+		// 格式不合法的行记入校验报告, 继续解析其余行
+		region, err := p.parseLine(line)
+		if err != nil {
+			items = append(items, item{line: lineNum, value: line, reason: err.Error()})
+			continue
+		}
+
+		items = append(items, item{line: lineNum, value: region})
+	}
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("scan file error: %w", err)
+	}
+
+	return p.base.parseItems(items)
+}
+
+// parseLine 将 BED 行转换为 chr17:43044295-43125483 格式的区间, 返回的错误作为校验报告中的原因
+func (p *BEDParser) parseLine(line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return "", fmt.Errorf("invalid bed line, at least 3 columns are required")
+	}
+
+	start, err := strconv.Atoi(fields[1])
+	if err != nil || start < 0 {
+		return "", fmt.Errorf("invalid bed start '%s', expected a non-negative integer", fields[1])
+	}
+
+	stop, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "", fmt.Errorf("invalid bed end '%s', expected an integer", fields[2])
+	}
+
+	// 半开区间 [start, end) 的 end 不大于 start 时不包含任何碱基
+	if stop <= start {
+		return "", fmt.Errorf("empty bed interval, end %d must be greater than start %d", stop, start)
+	}
+
+	return fmt.Sprintf("%s:%d-%d", fields[0], start+1, stop), nil
+}
+
+// Report 返回最近一次解析的校验报告
+func (p *BEDParser) Report() *ValidationReport {
+	return p.base.Report()
+}
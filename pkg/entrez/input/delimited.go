@@ -0,0 +1,51 @@
+package input
+
+import (
+	"encoding/csv"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// DelimitedParser 实现 csv/tsv 格式的查询文件解析器
+type DelimitedParser struct {
+	base    *FileParser
+	comma   rune
+	options TableOptions
+}
+
+// NewDelimitedParser 创建新的分隔符文件解析器, comma 为字段分隔符
+func NewDelimitedParser(base *FileParser, comma rune, options TableOptions) *DelimitedParser {
+	return &DelimitedParser{
+		base:    base,
+		comma:   comma,
+		options: options,
+	}
+}
+
+// Parse 从 reader 解析查询
+func (p *DelimitedParser) Parse(reader io.Reader) ([]*types.Query, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = p.comma
+	csvReader.FieldsPerRecord = -1 // 允许每行列数不同
+	csvReader.LazyQuotes = true
+
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "failed to read delimited file: %v", err)
+	}
+
+	items, err := columnItems(rows, p.options)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.base.parseItems(items)
+}
+
+// Report 返回最近一次解析的校验报告
+func (p *DelimitedParser) Report() *ValidationReport {
+	return p.base.Report()
+}
@@ -0,0 +1,59 @@
+package input
+
+import (
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+)
+
+// ExcelParser 实现 xlsx 格式的查询文件解析器
+type ExcelParser struct {
+	base    *FileParser
+	options TableOptions
+}
+
+// NewExcelParser 创建新的 Excel 文件解析器
+func NewExcelParser(base *FileParser, options TableOptions) *ExcelParser {
+	return &ExcelParser{
+		base:    base,
+		options: options,
+	}
+}
+
+// Parse 从 reader 解析查询
+func (p *ExcelParser) Parse(reader io.Reader) ([]*types.Query, error) {
+	f, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "failed to open excel file: %v", err)
+	}
+	defer f.Close()
+
+	sheet := p.options.Sheet
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.Wrapf(customerrors.ErrInput, "excel file has no sheets")
+		}
+		sheet = sheets[0]
+	}
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "failed to read sheet '%s': %v", sheet, err)
+	}
+
+	items, err := columnItems(rows, p.options)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.base.parseItems(items)
+}
+
+// Report 返回最近一次解析的校验报告
+func (p *ExcelParser) Report() *ValidationReport {
+	return p.base.Report()
+}
@@ -0,0 +1,125 @@
+package input
+
+import (
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 支持的输入文件格式
+const (
+	FormatTXT  = "txt"
+	FormatBED  = "bed"
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+	FormatXLSX = "xlsx"
+)
+
+// TableOptions 定义表格类输入文件 (csv、tsv、xlsx) 的解析选项
+type TableOptions struct {
+	Column string // 检索词所在列, 可为列名 (需要表头) 或从 1 开始的列号
+	Sheet  string // xlsx 工作表名称, 为空时使用第一个工作表
+	Header bool   // 首行是否为表头
+}
+
+// SupportedFormats 返回所有支持的输入文件格式
+func SupportedFormats() []string {
+	return []string{FormatTXT, FormatBED, FormatCSV, FormatTSV, FormatXLSX}
+}
+
+// DetectFormat 根据文件扩展名识别输入文件格式
+func DetectFormat(filename string) string {
+	// 标准输入没有扩展名, 默认按每行一个检索词处理
+	if filename == Stdin {
+		return FormatTXT
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	switch ext {
+	case "tab":
+		return FormatTSV
+	default:
+		return ext
+	}
+}
+
+// NewParser 根据输入文件格式创建解析器
+// base 提供分批大小、检索类型等通用配置
+func NewParser(format string, base *FileParser, options TableOptions) (Parser, error) {
+	switch strings.ToLower(format) {
+	case FormatTXT:
+		return base, nil
+	case FormatBED:
+		return NewBEDParser(base), nil
+	case FormatCSV:
+		return NewDelimitedParser(base, ',', options), nil
+	case FormatTSV:
+		return NewDelimitedParser(base, '\t', options), nil
+	case FormatXLSX:
+		return NewExcelParser(base, options), nil
+	default:
+		return nil, errors.Wrapf(customerrors.ErrInput, "unsupported input format '%s', use one of: %s",
+			format, strings.Join(SupportedFormats(), ", "))
+	}
+}
+
+// resolveColumn 根据表头解析检索词所在列，返回从 0 开始的列索引
+func resolveColumn(column string, header []string, hasHeader bool) (int, error) {
+	column = strings.TrimSpace(column)
+	if column == "" {
+		return 0, nil // 默认使用第一列
+	}
+
+	// 优先按列号处理
+	if index, err := strconv.Atoi(column); err == nil {
+		if index <= 0 {
+			return 0, errors.Wrapf(customerrors.ErrInput, "invalid column number %d, column numbers start at 1", index)
+		}
+		return index - 1, nil
+	}
+
+	if !hasHeader {
+		return 0, errors.Wrapf(customerrors.ErrInput, "column '%s' is not a number, column names require a header row", column)
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+
+	return 0, errors.Wrapf(customerrors.ErrInput, "column '%s' not found in header", column)
+}
+
+// columnItems 从表格行中提取指定列的检索词, 行号从 1 开始
+func columnItems(rows [][]string, options TableOptions) ([]item, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var header []string
+	firstRow := 0
+	if options.Header {
+		header = rows[0]
+		firstRow = 1
+	}
+
+	index, err := resolveColumn(options.Column, header, options.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]item, 0, len(rows)-firstRow)
+	for i := firstRow; i < len(rows); i++ {
+		// 跳过缺少该列的行
+		if index >= len(rows[i]) {
+			continue
+		}
+		items = append(items, item{line: i + 1, value: rows[i][index]})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,201 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 基因名解析来源
+const (
+	SymbolApproved = "approved" // HGNC 批准的基因名
+	SymbolPrevious = "previous" // 曾用名
+	SymbolAlias    = "alias"    // 别名
+)
+
+// hgncMaxLineSize hgnc_complete_set.txt 单行最大长度
+const hgncMaxLineSize = 1 << 20
+
+// Resolution 定义基因名的解析结果
+type Resolution struct {
+	Input      string   // 输入的基因名
+	Symbol     string   // 解析后的基因名, 无法解析时与输入相同
+	Source     string   // 解析来源, 在 HGNC 中未找到时为空
+	Candidates []string // 有歧义时的所有候选基因名
+}
+
+// Changed 检查基因名是否被解析为其他基因名
+func (r Resolution) Changed() bool {
+	return !strings.EqualFold(r.Input, r.Symbol)
+}
+
+// Ambiguous 检查基因名是否对应多个批准的基因名
+func (r Resolution) Ambiguous() bool {
+	return len(r.Candidates) > 1
+}
+
+// SymbolResolver 基于本地 HGNC 文件将别名及曾用名解析为批准的基因名
+// 文件下载: https://www.genenames.org/download/statistics-and-files/ (hgnc_complete_set.txt)
+type SymbolResolver struct {
+	approved map[string]string   // 大写基因名 -> 批准的基因名
+	previous map[string][]string // 大写曾用名 -> 批准的基因名
+	aliases  map[string][]string // 大写别名 -> 批准的基因名
+}
+
+// NewSymbolResolver 从 hgnc_complete_set.txt 文件创建基因名解析器
+func NewSymbolResolver(filename string) (*SymbolResolver, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(customerrors.ErrFailedOpenFile, "failed to open hgnc file %s: %v", filename, err)
+	}
+	defer file.Close()
+
+	return LoadSymbolResolver(file)
+}
+
+// LoadSymbolResolver 从 reader 读取 HGNC 数据创建基因名解析器
+func LoadSymbolResolver(reader io.Reader) (*SymbolResolver, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), hgncMaxLineSize)
+
+	if !scanner.Scan() {
+		return nil, errors.Wrapf(customerrors.ErrParse, "hgnc file is empty")
+	}
+
+	// 根据表头定位所需的列
+	columns := make(map[string]int)
+	for i, name := range strings.Split(scanner.Text(), "\t") {
+		columns[strings.Trim(name, "\" ")] = i
+	}
+
+	symbolCol, ok := columns["symbol"]
+	if !ok {
+		return nil, errors.Wrapf(customerrors.ErrParse, "hgnc file has no 'symbol' column")
+	}
+	aliasCol, hasAlias := columns["alias_symbol"]
+	prevCol, hasPrev := columns["prev_symbol"]
+	statusCol, hasStatus := columns["status"]
+
+	r := &SymbolResolver{
+		approved: make(map[string]string),
+		previous: make(map[string][]string),
+		aliases:  make(map[string][]string),
+	}
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if symbolCol >= len(fields) {
+			continue
+		}
+
+		// 只保留批准的基因, 跳过已撤销的条目
+		if hasStatus && statusCol < len(fields) && !strings.EqualFold(strings.Trim(fields[statusCol], "\""), "Approved") {
+			continue
+		}
+
+		symbol := strings.Trim(fields[symbolCol], "\" ")
+		if symbol == "" {
+			continue
+		}
+		r.approved[strings.ToUpper(symbol)] = symbol
+
+		if hasPrev && prevCol < len(fields) {
+			addSymbols(r.previous, fields[prevCol], symbol)
+		}
+		if hasAlias && aliasCol < len(fields) {
+			addSymbols(r.aliases, fields[aliasCol], symbol)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "failed to read hgnc file: %v", err)
+	}
+
+	return r, nil
+}
+
+// addSymbols 将 | 分隔的别名或曾用名映射到批准的基因名
+func addSymbols(m map[string][]string, value, symbol string) {
+	for _, name := range strings.Split(strings.Trim(value, "\""), "|") {
+		key := strings.ToUpper(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		m[key] = append(m[key], symbol)
+	}
+}
+
+// Resolve 解析基因名, 优先级: 批准的基因名 > 曾用名 > 别名
+// 曾用名或别名对应多个批准的基因名时不做解析，由调用方给出警告
+func (r *SymbolResolver) Resolve(symbol string) Resolution {
+	key := strings.ToUpper(symbol)
+	resolution := Resolution{Input: symbol, Symbol: symbol}
+
+	if approved, ok := r.approved[key]; ok {
+		resolution.Symbol = approved
+		resolution.Source = SymbolApproved
+		return resolution
+	}
+
+	for _, candidate := range []struct {
+		source  string
+		symbols map[string][]string
+	}{
+		{SymbolPrevious, r.previous},
+		{SymbolAlias, r.aliases},
+	} {
+		symbols := uniqueSymbols(candidate.symbols[key])
+		if len(symbols) == 0 {
+			continue
+		}
+
+		resolution.Source = candidate.source
+		resolution.Candidates = symbols
+		if len(symbols) == 1 {
+			resolution.Symbol = symbols[0]
+		}
+		return resolution
+	}
+
+	return resolution
+}
+
+// Describe 返回解析结果的说明，无需说明时返回空字符串
+func (r Resolution) Describe() string {
+	switch {
+	case r.Source == "":
+		return "not found in hgnc"
+	case r.Ambiguous():
+		return fmt.Sprintf("ambiguous %s symbol, candidates: %s; kept as is", r.Source, strings.Join(r.Candidates, ", "))
+	case r.Changed():
+		return fmt.Sprintf("resolved from %s symbol %s", r.Source, r.Input)
+	default:
+		return ""
+	}
+}
+
+// uniqueSymbols 对基因名去重并排序
+func uniqueSymbols(symbols []string) []string {
+	if len(symbols) <= 1 {
+		return symbols
+	}
+
+	seen := make(map[string]struct{}, len(symbols))
+	var unique []string
+	for _, symbol := range symbols {
+		if _, ok := seen[symbol]; ok {
+			continue
+		}
+		seen[symbol] = struct{}{}
+		unique = append(unique, symbol)
+	}
+	sort.Strings(unique)
+
+	return unique
+}
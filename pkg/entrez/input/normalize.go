@@ -0,0 +1,77 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// forbiddenChars 会破坏 Entrez 检索式的字符
+const forbiddenChars = "\"[]"
+
+// booleanOperators Entrez 检索式中的布尔运算符
+var booleanOperators = []string{"AND", "OR", "NOT"}
+
+// stripComment 去除检索词中的注释及首尾空白, 返回 false 表示该行为空行或注释行
+func stripComment(value string) (string, bool) {
+	if index := strings.Index(value, "#"); index >= 0 {
+		value = value[:index]
+	}
+
+	value = strings.TrimSpace(value)
+
+	return value, value != ""
+}
+
+// normalizeTerm 根据检索类型规范化检索词并校验其是否可用于构建 Entrez 检索式
+func normalizeTerm(value, flag string) (string, error) {
+	if strings.ContainsAny(value, forbiddenChars) {
+		return "", fmt.Errorf("contains characters not allowed in entrez terms (%s)", forbiddenChars)
+	}
+
+	for _, field := range strings.Fields(value) {
+		for _, operator := range booleanOperators {
+			if strings.EqualFold(field, operator) {
+				return "", fmt.Errorf("contains boolean operator '%s'", field)
+			}
+		}
+	}
+
+	switch flag {
+	case types.FlagRsID:
+		value = strings.ToLower(value)
+		if !rsIDPattern.MatchString(value) {
+			return "", fmt.Errorf("invalid rs id, expected format: rs80357906")
+		}
+	case types.FlagAccession:
+		value = strings.ToUpper(value)
+		if !accessionPattern.MatchString(value) {
+			return "", fmt.Errorf("invalid accession, expected format: VCV000017661 or RCV000031246")
+		}
+	case types.FlagVariationID:
+		if !variationIDPattern.MatchString(value) {
+			return "", fmt.Errorf("invalid variation id, expected a number")
+		}
+	case types.FlagHGVS:
+		if !hgvsPattern.MatchString(value) {
+			return "", fmt.Errorf("invalid hgvs expression, expected format: NM_007294.4:c.5266dup")
+		}
+	case types.FlagRegion:
+		region, err := ParseRegion(value, "")
+		if err != nil {
+			return "", fmt.Errorf("invalid region, expected format: chr17:43044295-43125483")
+		}
+		value = region.String()
+	default:
+		if strings.ContainsAny(value, "()") {
+			return "", fmt.Errorf("contains parentheses")
+		}
+		if len(strings.Fields(value)) > 1 {
+			return "", fmt.Errorf("contains whitespace, put one gene symbol per line")
+		}
+		value = strings.ToUpper(value)
+	}
+
+	return value, nil
+}
@@ -4,9 +4,9 @@ import (
 	"bufio"
 	"fmt"
 	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/utils"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
 	"io"
-	"os"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -15,78 +15,237 @@ import (
 // FileParser 实现基因查询文件解析器
 type FileParser struct {
 	batchSize int
-	flag      string
+	flag      string            // 检索字段标志，为 types.FlagMixed 时按行自动识别
+	assembly  string            // 区间检索时使用的参考基因组版本
+	report    *ValidationReport // 最近一次解析的校验报告
+	resolver  *SymbolResolver   // 基因名解析器, 为空时不解析别名及曾用名
+}
+
+// FileParserOption 定义文件解析器的配置选项
+type FileParserOption func(p *FileParser)
+
+// WithAssembly 设置区间检索时使用的参考基因组版本
+func WithAssembly(assembly string) FileParserOption {
+	return func(p *FileParser) {
+		if assembly != "" {
+			p.assembly = assembly
+		}
+	}
+}
+
+// WithResolver 设置基因名解析器，在构建查询前将别名及曾用名解析为批准的基因名
+func WithResolver(resolver *SymbolResolver) FileParserOption {
+	return func(p *FileParser) {
+		p.resolver = resolver
+	}
 }
 
 // NewFileParser 创建新的文件解析器
-func NewFileParser(batchSize int, flag string) *FileParser {
-	return &FileParser{
+func NewFileParser(batchSize int, flag string, options ...FileParserOption) *FileParser {
+	p := &FileParser{
 		batchSize: batchSize,
 		flag:      flag,
+		assembly:  types.AssemblyGRCh38,
+		report:    NewValidationReport(),
+	}
+
+	for _, option := range options {
+		option(p)
 	}
+
+	return p
+}
+
+// item 定义从输入文件中读取到的单个检索词
+type item struct {
+	line   int    // 所在行号
+	value  string // 原始内容
+	reason string // 读取时已判定为格式不合法的原因, 非空时直接记为 rejected
+}
+
+// searchTerm 定义单个检索词
+type searchTerm struct {
+	text   string        // 已添加检索字段标志的检索词
+	region *types.Region // 区间检索时对应的基因组区间
+	input  string        // 基因名被解析为其他基因名时的原始基因名
 }
 
 // ParseFile 解析输入文件生成查询列表
 func (p *FileParser) ParseFile(filename string) ([]*types.Query, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, errors.Wrapf(customerrors.ErrFailedOpenFile, "failed to open file %s: %v", filename, err)
-	}
-	defer file.Close()
-
-	return p.Parse(file)
+	return ParseFile(p, filename)
 }
 
-// Parse 从 reader 解析查询
+// Parse 从 reader 解析查询, 每行为一个检索词
 func (p *FileParser) Parse(reader io.Reader) ([]*types.Query, error) {
 	scanner := bufio.NewScanner(reader)
-	tempMap := make(map[string]struct{}) // 去重 map
-	var searchItemList []*types.Query
-	var lines []string
-
-	sep := " OR " // 多个基因串之间的分隔符，必须是大写且左右两边留有空格
+	var items []item
+	lineNum := 0
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		if _, ok := tempMap[line]; !ok {
-			tempMap[line] = struct{}{}
-
-			switch p.batchSize {
-			case 1:
-				searchItemList = append(searchItemList, types.NewQuery(line+p.flag))
-			default:
-				lines = append(lines, line)
-				if len(lines) == p.batchSize {
-					query := p.buildBatchQuery(lines, sep)
-					searchItemList = append(searchItemList, types.NewQuery(query))
-					lines = lines[:0]
-				}
-			}
-		}
-	}
-
-	// 处理最后一批数据
-	if p.batchSize != 1 && len(lines) > 0 {
-		query := p.buildBatchQuery(lines, sep)
-		searchItemList = append(searchItemList, types.NewQuery(query))
+		lineNum++
+		items = append(items, item{line: lineNum, value: scanner.Text()})
 	}
 
 	if err := scanner.Err(); err != nil && err != io.EOF {
 		return nil, fmt.Errorf("scan file error: %w", err)
 	}
 
+	return p.parseItems(items)
+}
+
+// parseItems 将各格式解析器读取到的检索词规范化、去重后构建为查询列表
+// 每个检索词的校验结果会记录在校验报告中
+func (p *FileParser) parseItems(items []item) ([]*types.Query, error) {
+	if !types.IsValidAssembly(p.assembly) {
+		return nil, errors.Wrapf(customerrors.ErrInput, "unsupported assembly '%s', use %s or %s",
+			p.assembly, types.AssemblyGRCh37, types.AssemblyGRCh38)
+	}
+
+	p.report = NewValidationReport()
+	seen := make(map[string]int)            // 去重 map, 值为首次出现的行号
+	groups := make(map[string][]searchTerm) // 按检索字段标志分组的检索词
+	var flags []string                      // 记录各检索字段标志首次出现的顺序
+
+	for _, it := range items {
+		if it.reason != "" {
+			p.report.add(it, "", p.flag, StatusRejected, it.reason)
+			continue
+		}
+
+		// 跳过空行和注释
+		value, ok := stripComment(it.value)
+		if !ok {
+			continue
+		}
+
+		// 混合模式下按行识别检索字段标志
+		flag := p.flag
+		if flag == types.FlagMixed {
+			flag = DetectFlag(value)
+		}
+
+		normalized, err := normalizeTerm(value, flag)
+		if err != nil {
+			p.report.add(it, "", flag, StatusRejected, err.Error())
+			continue
+		}
+
+		// 解析基因别名及曾用名, 去重基于解析后的基因名
+		var resolution Resolution
+		var note string
+		if flag == types.FlagGene && p.resolver != nil {
+			resolution = p.resolver.Resolve(normalized)
+			normalized = resolution.Symbol
+			note = resolution.Describe()
+		}
+
+		key := flag + strings.ToUpper(normalized)
+		if line, ok := seen[key]; ok {
+			p.report.add(it, normalized, flag, StatusDuplicate, fmt.Sprintf("duplicate of line %d", line))
+			continue
+		}
+		seen[key] = it.line
+
+		term, err := p.buildTerm(normalized, flag)
+		if err != nil {
+			p.report.add(it, normalized, flag, StatusRejected, err.Error())
+			continue
+		}
+
+		if resolution.Changed() {
+			term.input = resolution.Input
+		}
+		p.report.add(it, normalized, flag, StatusAccepted, note)
+
+		if _, ok := groups[flag]; !ok {
+			flags = append(flags, flag)
+		}
+		groups[flag] = append(groups[flag], term)
+	}
+
+	var searchItemList []*types.Query
+	for _, flag := range flags {
+		searchItemList = append(searchItemList, p.buildQueries(groups[flag], flag)...)
+	}
+
 	return searchItemList, nil
 }
 
-// buildBatchQuery 构建批量查询字符串
-func (p *FileParser) buildBatchQuery(lines []string, sep string) string {
+// Report 返回最近一次解析的校验报告
+func (p *FileParser) Report() *ValidationReport {
+	return p.report
+}
+
+// buildTerm 为单行内容构建检索词
+func (p *FileParser) buildTerm(line, flag string) (searchTerm, error) {
+	if flag != types.FlagRegion {
+		return searchTerm{text: getSearchRule(flag).formatTerm(line, flag)}, nil
+	}
+
+	region, err := ParseRegion(line, p.assembly)
+	if err != nil {
+		return searchTerm{}, err
+	}
+
+	return searchTerm{text: region.Term(), region: region}, nil
+}
+
+// buildQueries 按检索类型的分批规则将检索词合并为查询
+func (p *FileParser) buildQueries(terms []searchTerm, flag string) []*types.Query {
+	batchSize := getSearchRule(flag).getBatchSize(p.batchSize)
+	if batchSize <= 0 {
+		batchSize = len(terms) // 未设置分批大小时合并为一个查询
+	}
+
+	sep := " OR " // 多个检索词之间的分隔符，必须是大写且左右两边留有空格
+
+	var queries []*types.Query
+	for start := 0; start < len(terms); start += batchSize {
+		end := utils.Min(start+batchSize, len(terms))
+		query := p.buildBatchQuery(terms[start:end], sep)
+		query.SearchType = flag
+		if flag == types.FlagVariationID {
+			query.IDs = variationIDs(terms[start:end])
+		}
+		queries = append(queries, query)
+	}
+
+	return queries
+}
+
+// buildBatchQuery 构建批量查询
+func (p *FileParser) buildBatchQuery(terms []searchTerm, sep string) *types.Query {
 	var builder strings.Builder
-	for i, line := range lines {
-		if i == 0 {
-			builder.WriteString(line + p.flag)
-		} else {
-			builder.WriteString(sep + line + p.flag)
+	var regions []types.Region
+	resolved := make(map[string]string)
+	for i, term := range terms {
+		if i > 0 {
+			builder.WriteString(sep)
 		}
+		builder.WriteString(term.text)
+
+		if term.region != nil {
+			regions = append(regions, *term.region)
+		}
+		if term.input != "" {
+			resolved[strings.ToUpper(strings.TrimSuffix(term.text, types.FlagGene))] = term.input
+		}
+	}
+
+	query := types.NewQuery(builder.String())
+	query.Regions = regions
+	if len(resolved) > 0 {
+		query.ResolvedSymbols = resolved
+	}
+
+	return query
+}
+
+// variationIDs 返回检索词中的 VariationID
+func variationIDs(terms []searchTerm) []string {
+	ids := make([]string, 0, len(terms))
+	for _, term := range terms {
+		ids = append(ids, strings.TrimSuffix(term.text, types.FlagVariationID))
 	}
-	return builder.String()
+	return ids
 }
@@ -0,0 +1,119 @@
+package input
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 检索词校验状态
+const (
+	StatusAccepted  = "accepted"  // 已接受
+	StatusDuplicate = "duplicate" // 与之前的检索词重复
+	StatusRejected  = "rejected"  // 格式不合法被拒绝
+)
+
+// Reporter 定义可提供输入校验报告的解析器
+type Reporter interface {
+	Report() *ValidationReport
+}
+
+// ValidationEntry 定义单个检索词的校验结果
+type ValidationEntry struct {
+	Line       int    // 所在行号
+	Input      string // 原始内容
+	Normalized string // 规范化后的检索词
+	Type       string // 检索类型
+	Status     string // 校验状态
+	Reason     string // 重复或被拒绝的原因，基因名解析时为解析说明
+}
+
+// ValidationReport 定义输入文件的逐行校验报告
+type ValidationReport struct {
+	Entries []ValidationEntry
+}
+
+// NewValidationReport 创建新的校验报告
+func NewValidationReport() *ValidationReport {
+	return &ValidationReport{
+		Entries: make([]ValidationEntry, 0),
+	}
+}
+
+// add 添加一条校验结果
+func (r *ValidationReport) add(it item, normalized, flag, status, reason string) {
+	r.Entries = append(r.Entries, ValidationEntry{
+		Line:       it.line,
+		Input:      it.value,
+		Normalized: normalized,
+		Type:       strings.Trim(flag, "[]"),
+		Status:     status,
+		Reason:     reason,
+	})
+}
+
+// Count 返回指定状态的检索词数量
+func (r *ValidationReport) Count(status string) int {
+	count := 0
+	for _, entry := range r.Entries {
+		if entry.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Rejected 返回所有被拒绝的检索词
+func (r *ValidationReport) Rejected() []ValidationEntry {
+	var rejected []ValidationEntry
+	for _, entry := range r.Entries {
+		if entry.Status == StatusRejected {
+			rejected = append(rejected, entry)
+		}
+	}
+	return rejected
+}
+
+// Notes 返回所有附带说明的已接受检索词 (如基因名解析结果)
+func (r *ValidationReport) Notes() []ValidationEntry {
+	var notes []ValidationEntry
+	for _, entry := range r.Entries {
+		if entry.Status == StatusAccepted && entry.Reason != "" {
+			notes = append(notes, entry)
+		}
+	}
+	return notes
+}
+
+// Save 将校验报告以 tsv 格式保存到文件
+func (r *ValidationReport) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create validation report %s", filename)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Comma = '\t'
+
+	records := [][]string{{"line", "input", "normalized", "type", "status", "reason"}}
+	for _, entry := range r.Entries {
+		records = append(records, []string{
+			strconv.Itoa(entry.Line),
+			entry.Input,
+			entry.Normalized,
+			entry.Type,
+			entry.Status,
+			entry.Reason,
+		})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return errors.Wrapf(err, "failed to write validation report %s", filename)
+	}
+
+	return nil
+}
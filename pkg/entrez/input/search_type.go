@@ -0,0 +1,113 @@
+package input
+
+import (
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	idBatchSize   = 100 // rs 号、VariationID、VCV/RCV 号通常只命中一条记录，可合并更多检索词
+	hgvsBatchSize = 20  // HGVS 表达式较长，合并过多会导致请求 url 过长
+)
+
+// 用于混合模式下按行识别标识符类型
+var (
+	rsIDPattern        = regexp.MustCompile(`^rs\d+$`) // 区分大小写, 避免将 RS1 等基因名识别为 rs 号
+	accessionPattern   = regexp.MustCompile(`(?i)^(VCV|RCV)\d{9}(\.\d+)?$`)
+	variationIDPattern = regexp.MustCompile(`^\d+$`)
+	hgvsPattern        = regexp.MustCompile(`^[A-Za-z]{2}_\d+(\.\d+)?(\([^)]+\))?:[cgmnpr]\.\S+$`)
+	regionPattern      = regexp.MustCompile(`(?i)^(?:chr)?([0-9]{1,2}|X|Y|MT?):([0-9,]+)-([0-9,]+)$`)
+)
+
+// searchRule 定义不同检索类型的检索词格式及分批规则
+type searchRule struct {
+	quote     bool // 检索词是否需要用双引号包裹 (HGVS 中含有 : > 等特殊字符)
+	batchSize int  // 单个查询最多合并的检索词数量，0 表示使用配置文件中的 batch_size
+}
+
+// getSearchRule 根据检索字段标志返回对应的检索规则
+func getSearchRule(flag string) searchRule {
+	switch flag {
+	case types.FlagRsID, types.FlagVariationID, types.FlagAccession:
+		return searchRule{batchSize: idBatchSize}
+	case types.FlagHGVS:
+		return searchRule{quote: true, batchSize: hgvsBatchSize}
+	default:
+		return searchRule{}
+	}
+}
+
+// getBatchSize 返回检索类型实际使用的分批大小
+func (r searchRule) getBatchSize(defaultSize int) int {
+	// batch_size 为 1 时表示不合并检索词，所有类型都遵循该设置
+	if r.batchSize == 0 || defaultSize == 1 {
+		return defaultSize
+	}
+	return r.batchSize
+}
+
+// formatTerm 为检索词添加检索字段标志
+func (r searchRule) formatTerm(term, flag string) string {
+	if r.quote {
+		return "\"" + term + "\"" + flag
+	}
+	return term + flag
+}
+
+// ParseRegion 解析 chr17:43044295-43125483 格式的基因组区间
+func ParseRegion(term, assembly string) (*types.Region, error) {
+	matches := regionPattern.FindStringSubmatch(strings.TrimSpace(term))
+	if matches == nil {
+		return nil, errors.Wrapf(customerrors.ErrInput, "invalid region '%s', expected format: chr17:43044295-43125483", term)
+	}
+
+	start, err := strconv.Atoi(strings.ReplaceAll(matches[2], ",", ""))
+	if err != nil {
+		return nil, errors.Wrapf(customerrors.ErrInput, "invalid region start '%s': %v", matches[2], err)
+	}
+	stop, err := strconv.Atoi(strings.ReplaceAll(matches[3], ",", ""))
+	if err != nil {
+		return nil, errors.Wrapf(customerrors.ErrInput, "invalid region stop '%s': %v", matches[3], err)
+	}
+
+	if start <= 0 || stop < start {
+		return nil, errors.Wrapf(customerrors.ErrInput, "invalid region '%s': start must be positive and not greater than stop", term)
+	}
+
+	chr := strings.ToUpper(matches[1])
+	if chr == "M" {
+		chr = "MT"
+	}
+
+	return &types.Region{
+		Chr:      chr,
+		Start:    start,
+		Stop:     stop,
+		Assembly: assembly,
+	}, nil
+}
+
+// DetectFlag 根据检索词的格式识别其检索字段标志，无法识别时按基因名处理
+func DetectFlag(term string) string {
+	term = strings.TrimSpace(term)
+
+	switch {
+	case rsIDPattern.MatchString(term):
+		return types.FlagRsID
+	case accessionPattern.MatchString(term):
+		return types.FlagAccession
+	case variationIDPattern.MatchString(term):
+		return types.FlagVariationID
+	case hgvsPattern.MatchString(term):
+		return types.FlagHGVS
+	case regionPattern.MatchString(term):
+		return types.FlagRegion
+	default:
+		return types.FlagGene
+	}
+}
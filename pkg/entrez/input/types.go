@@ -1,11 +1,33 @@
 package input
 
 import (
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
 	"io"
+	"os"
+
+	"github.com/pkg/errors"
 )
 
+// Stdin 表示从标准输入读取查询的文件名
+const Stdin = "-"
+
 // Parser 定义解析器接口
 type Parser interface {
 	Parse(reader io.Reader) ([]*types.Query, error)
 }
+
+// ParseFile 使用指定的解析器解析输入文件生成查询列表, 文件名为 - 时从标准输入读取
+func ParseFile(parser Parser, filename string) ([]*types.Query, error) {
+	if filename == Stdin {
+		return parser.Parse(os.Stdin)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(customerrors.ErrFailedOpenFile, "failed to open file %s: %v", filename, err)
+	}
+	defer file.Close()
+
+	return parser.Parse(file)
+}
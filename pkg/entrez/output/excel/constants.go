@@ -2,11 +2,20 @@ package excel
 
 const (
 	defaultRowHeight  = 25.0 // 设置默认行高为 25
-	defaultColCount   = 29   // 默认列数
+	defaultColCount   = 41   // 默认列数
 	activeStyle       = AlternatingRow
 	defaultBufferSize = 1000 // 默认缓冲区大小
 )
 
+// 来源相关列的位置, 所有查询完成后重新写入出现在多个查询中的变异
+const (
+	regionCheckCol   = 29 // AD: Region check
+	resolvedFromCol  = 30 // AE: Resolved from
+	sourceQueriesCol = 39 // AN: Source queries
+	sourceGenesCol   = 40 // AO: Source genes
+	variationIDCol   = 13 // N: VariationID
+)
+
 // 定义列宽常量
 var defaultColumnWidths = [defaultColCount]float64{
 	45, // A: Name
@@ -38,6 +47,18 @@ var defaultColumnWidths = [defaultColCount]float64{
 	26, // AA: Oncogenicity classification
 	32, // AB: Oncogenicity date last evaluated
 	28, // AC: Oncogenicity review status
+	20, // AD: Region check
+	28, // AE: Resolved from
+	22, // AF: Number of submitters
+	48, // AG: Submitters
+	60, // AH: Conflicting submitters
+	36, // AI: Citations (PubMed)
+	60, // AJ: HGVS (all transcripts)
+	36, // AK: PMIDs
+	28, // AL: MedGen CUIs
+	20, // AM: Linked Gene IDs
+	36, // AN: Source queries
+	24, // AO: Source genes
 }
 
 // 定义表头常量
@@ -71,5 +92,16 @@ var defaultHeaders = [defaultColCount]string{
 	"Oncogenicity classification",
 	"Oncogenicity date last evaluated",
 	"Oncogenicity review status",
-	// "Query", // 添加查询列，用于数据校对 （可删除）
+	"Region check",
+	"Resolved from",
+	"Number of submitters",
+	"Submitters",
+	"Conflicting submitters",
+	"Citations (PubMed)",
+	"HGVS (all transcripts)",
+	"PMIDs",
+	"MedGen CUIs",
+	"Linked Gene IDs",
+	"Source queries",
+	"Source genes",
 }
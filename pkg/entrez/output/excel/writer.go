@@ -1,8 +1,12 @@
 package excel
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"sync"
 
@@ -13,12 +17,20 @@ import (
 )
 
 type Writer struct {
-	file         *excelize.File
-	currentRow   int
-	streamWriter *excelize.StreamWriter
-	rowBuffer    [][]interface{}
-	mu           sync.Mutex
-	styles       ExcelStyle
+	file          *excelize.File
+	currentRow    int
+	streamWriter  *excelize.StreamWriter
+	streamFlushed bool // StreamWriter 是否已刷新
+	rowBuffer     [][]interface{}
+	spool         *os.File                       // 已处理的行, 保存时按顺序写入工作表
+	origins       map[string]map[int]interface{} // 出现在多个查询中的变异 -> 合并所有来源后的来源相关列
+	mu            sync.Mutex
+	styles        ExcelStyle
+}
+
+// init 注册 xlsx 格式的写入器
+func init() {
+	output.RegisterWriter(output.FormatXLSX, NewWriter)
 }
 
 func NewWriter(sheetName string) (output.Writer, error) {
@@ -46,8 +58,15 @@ func NewWriter(sheetName string) (output.Writer, error) {
 		return nil, fmt.Errorf("failed to init style: %w", err)
 	}
 
+	// StreamWriter 只能按顺序写入且写入后无法修改, 结果先写入临时文件, 所有来源合并后再写入工作表
+	spool, err := os.CreateTemp("", "clinvarDL-rows-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create row spool file: %w", err)
+	}
+
 	w := &Writer{
 		file:         f,
+		spool:        spool,
 		currentRow:   1,
 		streamWriter: sw,
 		rowBuffer:    make([][]interface{}, 0, defaultBufferSize),
@@ -223,34 +242,125 @@ func (ew *Writer) processResult(result *types.QueryResult) ([][]interface{}, err
 		row[26] = doc.OncogenicityClassification.Description
 		row[27] = doc.OncogenicityClassification.LastEvaluated
 		row[28] = doc.OncogenicityClassification.ReviewStatus
-		// row[29] = result.Query // 可删除
-
+		// 区间检索时标记区间外的变异; 输入的基因名经 HGNC 解析时记录原始基因名; 来源查询及命中的输入基因名
+		origin := doc.Origin
+		if origin == nil {
+			origin = &types.VariantOrigin{Regions: result.Regions, ResolvedSymbols: result.ResolvedSymbols}
+		}
+		for col, value := range originColumns(origin, genes, doc.VariationSet.Variation.VariationLoc.AssemblySet) {
+			row[col] = value
+		}
+		// 启用 efetch 补充阶段时填充提交者级别信息
+		if vcv := doc.VCV; vcv != nil {
+			row[31] = vcv.NumberOfSubmitters
+			row[32] = strings.Join(vcv.Submitters(), "|")
+			row[33] = vcv.ConflictingSubmitters()
+			row[34] = strings.Join(vcv.Citations(), "|")
+			row[35] = strings.Join(vcv.HGVS, "|")
+		}
+		// 启用 elink 补充阶段时填充关联记录
+		if links := doc.Links; links != nil {
+			row[36] = strings.Join(links.PMIDs, "|")
+			row[37] = strings.Join(links.MedGenCUIs, "|")
+			row[38] = strings.Join(links.GeneIDs, "|")
+		}
 		rows = append(rows, row)
 	}
 
 	return rows, nil
 }
 
+// UpdateOrigins 实现 output.OriginWriter 接口
+// 只记录出现在多个查询中的变异的来源相关列, 保存时替换该变异所在行的对应单元格
+func (ew *Writer) UpdateOrigins(variants []*types.SharedVariant) error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.streamFlushed {
+		return fmt.Errorf("failed to update origins: results have already been saved")
+	}
+
+	for _, variant := range variants {
+		if ew.origins == nil {
+			ew.origins = make(map[string]map[int]interface{}, len(variants))
+		}
+		ew.origins[variant.Uid] = originColumns(variant.Origin, variant.Genes, variant.Assemblies)
+	}
+
+	return nil
+}
+
 func (ew *Writer) Save(filename string) error {
 	ew.mu.Lock()
 	defer ew.mu.Unlock()
 
-	// 刷新并关闭 StreamWriter
+	if err := ew.flushStream(); err != nil {
+		return err
+	}
+
+	return ew.file.SaveAs(filename)
+}
+
+// flushStream 将所有行写入工作表后刷新并关闭 StreamWriter, 调用前需要持有锁
+func (ew *Writer) flushStream() error {
+	if ew.streamFlushed {
+		return nil
+	}
+
+	if err := ew.writeRows(); err != nil {
+		return err
+	}
+
 	if err := ew.streamWriter.Flush(); err != nil {
 		return fmt.Errorf("failed to flush stream writer: %w", err)
 	}
+	ew.streamFlushed = true
 
-	return ew.file.SaveAs(filename)
+	return nil
 }
 
 func (ew *Writer) Close() error {
+	ew.spool.Close()
+	os.Remove(ew.spool.Name())
 	return ew.file.Close()
 }
 
+// flushBuffer 将缓冲区中的行写入临时文件
 func (ew *Writer) flushBuffer() error {
+	encoder := json.NewEncoder(ew.spool)
 	for _, row := range ew.rowBuffer {
-		cell, _ := excelize.CoordinatesToCellName(1, ew.currentRow)
+		if err := encoder.Encode(row); err != nil {
+			return fmt.Errorf("failed to spool row: %w", err)
+		}
+	}
+
+	// 清空缓冲区
+	ew.rowBuffer = ew.rowBuffer[:0]
+	return nil
+}
+
+// writeRows 将临时文件中的行按顺序写入工作表, 出现在多个查询中的变异使用合并所有来源后的来源相关列
+func (ew *Writer) writeRows() error {
+	if _, err := ew.spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind row spool file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(ew.spool))
+	for {
+		var row []interface{}
+		if err := decoder.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read spooled row: %w", err)
+		}
+
+		if uid, ok := row[variationIDCol].(string); ok {
+			for col, value := range ew.origins[uid] {
+				row[col] = value
+			}
+		}
 
+		cell, _ := excelize.CoordinatesToCellName(1, ew.currentRow)
 		if err := ew.streamWriter.SetRow(cell, row, excelize.RowOpts{
 			Height:  defaultRowHeight,
 			StyleID: ew.styles.GetRowStyle(ew.currentRow), // 使用自定义样式
@@ -270,7 +380,32 @@ func (ew *Writer) flushBuffer() error {
 		return fmt.Errorf("failed to add table: %w", err)
 	}
 
-	// 清空缓冲区
-	ew.rowBuffer = ew.rowBuffer[:0]
 	return nil
 }
+
+// originColumns 返回与来源查询相关的列, 键为列序号
+// 去重后合并了多个来源查询的记录使用所有来源查询的区间及基因名映射
+func originColumns(origin *types.VariantOrigin, genes []string, assemblies []types.Assembly) map[int]interface{} {
+	return map[int]interface{}{
+		regionCheckCol:   types.CheckRegions(origin.Regions, assemblies),
+		resolvedFromCol:  resolvedFrom(genes, origin.ResolvedSymbols),
+		sourceQueriesCol: strings.Join(origin.Queries, "|"),
+		sourceGenesCol:   strings.Join(origin.Genes, "|"),
+	}
+}
+
+// resolvedFrom 返回变异所属基因中经 HGNC 解析的原始基因名, 格式: C10orf11->LRMDA
+func resolvedFrom(genes []string, resolved map[string]string) string {
+	if len(resolved) == 0 {
+		return ""
+	}
+
+	var mappings []string
+	for _, gene := range genes {
+		if input, ok := resolved[strings.ToUpper(gene)]; ok {
+			mappings = append(mappings, input+"->"+gene)
+		}
+	}
+
+	return strings.Join(mappings, "|")
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 支持的输出格式
+const (
+	FormatXLSX = "xlsx"
+)
+
+// WriterFactory 创建结果写入器, sheetName 为工作表名称, 不区分工作表的格式忽略该参数
+type WriterFactory func(sheetName string) (Writer, error)
+
+var (
+	registryMu     sync.RWMutex
+	writerRegistry = make(map[string]WriterFactory) // 输出格式 -> 写入器
+)
+
+// RegisterWriter 注册输出格式的写入器, 格式名称为文件扩展名 (不含 ".", 不区分大小写)
+// 各格式的写入器在其包的 init 中注册
+func RegisterWriter(format string, factory WriterFactory) error {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "" || factory == nil {
+		return fmt.Errorf("invalid output writer registration for format '%s'", format)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	writerRegistry[format] = factory
+	return nil
+}
+
+// NewWriter 根据输出格式创建结果写入器
+func NewWriter(format, sheetName string) (Writer, error) {
+	registryMu.RLock()
+	factory, ok := writerRegistry[strings.ToLower(format)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format '%s', use one of: %s", format, strings.Join(SupportedFormats(), ", "))
+	}
+
+	return factory(sheetName)
+}
+
+// SupportedFormats 返回已注册的输出格式
+func SupportedFormats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	formats := make([]string, 0, len(writerRegistry))
+	for format := range writerRegistry {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// DetectFormat 根据文件扩展名识别输出格式, 扩展名不是已注册的格式时返回空字符串
+func DetectFormat(filename string) string {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if _, ok := writerRegistry[format]; !ok {
+		return ""
+	}
+	return format
+}
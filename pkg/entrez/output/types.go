@@ -13,3 +13,9 @@ type Writer interface {
 	Save(filename string) error
 	Close() error
 }
+
+// OriginWriter 可选接口, 所有查询完成后更新出现在多个查询结果中的变异的来源相关列
+// 结果按批次流式写入, 变异所在的行只包含首次命中的查询, 完整的来源在最后一次性写回该行
+type OriginWriter interface {
+	UpdateOrigins(variants []*types.SharedVariant) error
+}
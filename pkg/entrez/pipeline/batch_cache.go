@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/cache"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// WithBatchCache 设置 esummary 批次的缓存
+// 每个批次完成后立即保存, 查询中断或超时后再次执行时只请求缺失的批次
+func WithBatchCache(batches cache.BatchCache) PipelineOption {
+	return func(p *Pipeline) {
+		p.Summary.batches = batches
+	}
+}
+
+// batchKey 返回查询的批次缓存键, 与查询结果的缓存键相同
+func (e *EsummaryExecutor) batchKey(query *types.Query) string {
+	return cache.NewFingerprint(query, e.Config).Key(query)
+}
+
+// cachedBatch 从缓存获取已完成的批次, count 为 esearch 返回的记录数, 未命中时返回 nil
+func (e *EsummaryExecutor) cachedBatch(query *types.Query, count int, info types.BatchInfo) *types.ESummaryResult {
+	if e.batches == nil {
+		return nil
+	}
+
+	result, err := e.batches.GetBatch(e.batchKey(query), count, info.Start, info.Size)
+	if err != nil {
+		return nil
+	}
+
+	return result
+}
+
+// saveBatch 保存已完成的批次, 保存失败不影响查询
+func (e *EsummaryExecutor) saveBatch(query *types.Query, count int, info types.BatchInfo, result *types.ESummaryResult) {
+	if e.batches == nil {
+		return
+	}
+
+	if err := e.batches.SetBatch(e.batchKey(query), count, info.Start, info.Size, result); err != nil {
+		logcdl.Warn("failed to cache esummary batch (start=%d) for query '%v': %v", info.Start, query, err)
+	}
+}
+
+// batchKeys 返回保存查询文档摘要的批次缓存键, 未设置批次缓存时返回 nil
+func (e *EsummaryExecutor) batchKeys(query *types.Query) []string {
+	if e.batches == nil {
+		return nil
+	}
+	return []string{e.batchKey(query)}
+}
@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	customHttp "github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/utils"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/service"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// EfetchExecutor 通过 efetch 获取完整 VCV 记录并附加到文档摘要
+type EfetchExecutor struct {
+	Efetch *service.EFetchOperation
+	Config *config.Config
+}
+
+func NewEfetchExecutor(config *config.Config, httpClient *customHttp.Client, rateLimiter *customHttp.RateLimiter) *EfetchExecutor {
+	efetchExecutor := &EfetchExecutor{
+		Efetch: service.NewEFetchOperation(httpClient, rateLimiter),
+		Config: config,
+	}
+
+	efetchExecutor.setupOperations()
+
+	return efetchExecutor
+}
+
+func (e *EfetchExecutor) setupOperations() {
+	// 配置 eFetch 查询参数
+	e.Efetch.SetDB(e.Config.EntrezParams.DB).
+		SetEmail(e.Config.EntrezParams.Email).
+		SetApiKey(e.Config.EntrezParams.ApiKey).
+		SetToolName(e.Config.EntrezParams.ToolName).
+		SetMaxResponseSize(e.Config.Runtime.MaxResponseSize)
+}
+
+// Name 实现 Enricher 接口
+func (e *EfetchExecutor) Name() string {
+	return "efetch vcv"
+}
+
+// Enrich 实现 Enricher 接口
+// 按 FetchBatchSize 将 [start, start+size) 拆分为多次 efetch 请求, 按 VariationID 将 VCV 记录附加到对应的文档摘要
+// searchResult 为 nil 时按文档摘要的 VariationID 请求
+func (e *EfetchExecutor) Enrich(ctx context.Context, searchResult *types.ESearchResult, start, size int, result *types.ESummaryResult, query *types.Query) error {
+	uids := make([]string, 0, len(result.DocumentSummarySet.DocumentSummary))
+	docs := make(map[string]*types.DocumentSummary, len(result.DocumentSummarySet.DocumentSummary))
+	for _, doc := range result.DocumentSummarySet.DocumentSummary {
+		uids = append(uids, doc.Uid)
+		docs[doc.Uid] = doc
+	}
+
+	batchSize := e.Config.Enrichment.FetchBatchSize
+	end := start + size
+	if searchResult == nil {
+		end = start + len(uids)
+	}
+	var failed, attached int
+
+	for batchStart := start; batchStart < end; batchStart += batchSize {
+		batchEnd := utils.Min(batchStart+batchSize, end)
+
+		retryConfig := retry.DefaultConfig()
+		fetchResult, err := retry.DoWithRetry(ctx, fmt.Sprintf("efetch vcv (start=%d) for query '%v'", batchStart, query), retryConfig, func() (*types.EFetchResult, error) {
+			var fetchResult *types.EFetchResult
+			var err error
+			if searchResult == nil {
+				fetchResult, err = e.Efetch.ExecuteIDs(ctx, uids[batchStart-start:batchEnd-start], query)
+			} else {
+				fetchResult, err = e.Efetch.Execute(ctx, searchResult.WebEnv, searchResult.QueryKey, batchStart, batchEnd-batchStart, query)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if fetchResult == nil || len(fetchResult.Records) == 0 {
+				return nil, customerrors.NewEmptyResultError("server returned no vcv records")
+			}
+
+			return fetchResult, nil
+		})
+		if err != nil {
+			failed++
+			logcdl.Error("efetch vcv (start=%d) failed after all retries for query '%v'", batchStart, query)
+			continue
+		}
+
+		for _, record := range fetchResult.Records {
+			if doc, ok := docs[record.VariationID]; ok {
+				doc.VCV = record
+				attached++
+			}
+		}
+	}
+
+	logcdl.Info("efetch attached %d/%d vcv records (start=%d) for query '%v'",
+		attached, len(docs), start, query)
+
+	if failed > 0 {
+		return fmt.Errorf("%d efetch requests failed", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	customHttp "github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/utils"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/service"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// ElinkExecutor 通过 elink 获取变异在 PubMed、MedGen、Gene 中的关联记录并附加到文档摘要
+type ElinkExecutor struct {
+	Elink  *service.ELinkOperation
+	MedGen *service.MedGenSummaryOperation // elink 返回的是 MedGen UID, 需要再通过 esummary 转换为概念 ID
+	Config *config.Config
+}
+
+func NewElinkExecutor(config *config.Config, httpClient *customHttp.Client, rateLimiter *customHttp.RateLimiter) *ElinkExecutor {
+	elinkExecutor := &ElinkExecutor{
+		Elink:  service.NewELinkOperation(httpClient, rateLimiter),
+		MedGen: service.NewMedGenSummaryOperation(httpClient, rateLimiter),
+		Config: config,
+	}
+
+	elinkExecutor.setupOperations()
+
+	return elinkExecutor
+}
+
+func (e *ElinkExecutor) setupOperations() {
+	// 配置 eLink 查询参数
+	e.Elink.SetDBFrom(e.Config.EntrezParams.DB).
+		SetEmail(e.Config.EntrezParams.Email).
+		SetApiKey(e.Config.EntrezParams.ApiKey).
+		SetToolName(e.Config.EntrezParams.ToolName).
+		SetMaxResponseSize(e.Config.Runtime.MaxResponseSize)
+
+	// 配置 MedGen eSummary 查询参数
+	e.MedGen.SetEmail(e.Config.EntrezParams.Email).
+		SetApiKey(e.Config.EntrezParams.ApiKey).
+		SetToolName(e.Config.EntrezParams.ToolName).
+		SetMaxResponseSize(e.Config.Runtime.MaxResponseSize)
+}
+
+// Name 实现 Enricher 接口
+func (e *ElinkExecutor) Name() string {
+	return "elink"
+}
+
+// Enrich 实现 Enricher 接口
+// elink 按 UID 分批请求, 不依赖 WebEnv; 每个目标数据库单独请求, 某个数据库失败不影响其他数据库
+func (e *ElinkExecutor) Enrich(ctx context.Context, _ *types.ESearchResult, start, _ int, result *types.ESummaryResult, query *types.Query) error {
+	docs := result.DocumentSummarySet.DocumentSummary
+	uids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		uids = append(uids, doc.Uid)
+	}
+
+	var failed int
+	links := make(map[string]map[string][]string, len(e.Config.Enrichment.Links)) // 目标数据库 -> UID -> 关联 UID
+	for _, dbTo := range e.Config.Enrichment.Links {
+		linked, err := e.link(ctx, dbTo, uids, start, query)
+		if err != nil {
+			failed++
+		}
+
+		// MedGen UID 转换为概念 ID
+		if dbTo == types.LinkMedGen && len(linked) > 0 {
+			if linked, err = e.medGenConcepts(ctx, linked, start, query); err != nil {
+				failed++
+			}
+		}
+
+		links[dbTo] = linked
+	}
+
+	for _, doc := range docs {
+		doc.Links = &types.VariantLinks{
+			PMIDs:      links[types.LinkPubMed][doc.Uid],
+			MedGenCUIs: links[types.LinkMedGen][doc.Uid],
+			GeneIDs:    links[types.LinkGene][doc.Uid],
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d elink requests failed", failed)
+	}
+
+	return nil
+}
+
+// link 按 LinkBatchSize 分批获取 uids 在目标数据库中的关联 UID, 返回已成功获取的部分
+func (e *ElinkExecutor) link(ctx context.Context, dbTo string, uids []string, start int, query *types.Query) (map[string][]string, error) {
+	linked := make(map[string][]string, len(uids))
+	batchSize := e.Config.Enrichment.LinkBatchSize
+
+	var lastErr error
+	for i := 0; i < len(uids); i += batchSize {
+		batch := uids[i:utils.Min(i+batchSize, len(uids))]
+
+		retryConfig := retry.DefaultConfig()
+		linkResult, err := retry.DoWithRetry(ctx, fmt.Sprintf("elink %s (start=%d) for query '%v'", dbTo, start+i, query), retryConfig, func() (*types.ELinkResult, error) {
+			return e.Elink.Execute(ctx, dbTo, batch, query)
+		})
+		if err != nil {
+			lastErr = err
+			logcdl.Error("elink %s (start=%d) failed after all retries for query '%v'", dbTo, start+i, query)
+			continue
+		}
+
+		for uid, ids := range linkResult.Links {
+			linked[uid] = ids
+		}
+	}
+
+	return linked, lastErr
+}
+
+// medGenConcepts 将关联的 MedGen UID 转换为概念 ID, 无法转换的 UID 被丢弃
+func (e *ElinkExecutor) medGenConcepts(ctx context.Context, linked map[string][]string, start int, query *types.Query) (map[string][]string, error) {
+	var medGenUIDs []string
+	seen := make(map[string]struct{})
+	for _, ids := range linked {
+		for _, id := range ids {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			medGenUIDs = append(medGenUIDs, id)
+		}
+	}
+
+	concepts := make(map[string]string, len(medGenUIDs))
+	batchSize := e.Config.Enrichment.LinkBatchSize
+
+	var lastErr error
+	for i := 0; i < len(medGenUIDs); i += batchSize {
+		batch := medGenUIDs[i:utils.Min(i+batchSize, len(medGenUIDs))]
+
+		retryConfig := retry.DefaultConfig()
+		summary, err := retry.DoWithRetry(ctx, fmt.Sprintf("medgen esummary (start=%d) for query '%v'", start, query), retryConfig, func() (*types.MedGenSummaryResult, error) {
+			return e.MedGen.Execute(ctx, batch, query)
+		})
+		if err != nil {
+			lastErr = err
+			logcdl.Error("medgen esummary (start=%d) failed after all retries for query '%v'", start, query)
+			continue
+		}
+
+		for uid, conceptId := range summary.Concepts {
+			concepts[uid] = conceptId
+		}
+	}
+
+	cuis := make(map[string][]string, len(linked))
+	for uid, ids := range linked {
+		for _, id := range ids {
+			if conceptId, ok := concepts[id]; ok {
+				cuis[uid] = append(cuis[uid], conceptId)
+			}
+		}
+	}
+
+	return cuis, lastErr
+}
@@ -0,0 +1,38 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// Enricher 定义 esummary 之后的可选补充阶段
+// 每个 esummary 批次成功后调用, 为该批次的文档摘要附加更多信息
+type Enricher interface {
+	// Name 返回补充阶段名称, 用于日志
+	Name() string
+	// Enrich 使用 esearch 返回的 WebEnv 及 query_key 补充 [start, start+size) 范围内的文档摘要
+	// searchResult 为 nil 时 (如核对时按 UID 补齐的记录) 记录不在 esearch 结果的对应范围内, 需要按文档摘要的 UID 请求
+	Enrich(ctx context.Context, searchResult *types.ESearchResult, start, size int, result *types.ESummaryResult, query *types.Query) error
+}
+
+// AddEnricher 添加 esummary 之后的补充阶段
+func (e *EsummaryExecutor) AddEnricher(enricher Enricher) *EsummaryExecutor {
+	e.enrichers = append(e.enrichers, enricher)
+	return e
+}
+
+// enrich 依次执行所有补充阶段
+// 补充阶段是可选的, 失败时只打印警告, 不影响 esummary 批次的结果
+func (e *EsummaryExecutor) enrich(ctx context.Context, searchResult *types.ESearchResult, start, size int, result *types.ESummaryResult, query *types.Query) {
+	if result == nil || len(result.DocumentSummarySet.DocumentSummary) == 0 {
+		return
+	}
+
+	for _, enricher := range e.enrichers {
+		if err := enricher.Enrich(ctx, searchResult, start, size, result, query); err != nil {
+			logcdl.Warn("%s enrichment (start=%d) incomplete for query '%v': %v", enricher.Name(), start, query, err)
+		}
+	}
+}
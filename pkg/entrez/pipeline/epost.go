@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	customeHttp "github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/service"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// EpostExecutor 将查询中的 UID 列表上传至历史服务器, 代替 esearch 为 esummary 提供 WebEnv 及 query_key
+type EpostExecutor struct {
+	Epost  *service.EPostOperation
+	Config *config.Config
+}
+
+func NewEpostExecutor(config *config.Config, httpClient *customeHttp.Client, rateLimiter *customeHttp.RateLimiter) *EpostExecutor {
+	epostExecutor := &EpostExecutor{
+		Epost:  service.NewEPostOperation(httpClient, rateLimiter),
+		Config: config,
+	}
+
+	epostExecutor.setupOperations()
+
+	return epostExecutor
+}
+
+func (e *EpostExecutor) setupOperations() {
+	// 配置 ePost 查询参数
+	e.Epost.SetDB(e.Config.EntrezParams.DB).
+		SetEmail(e.Config.EntrezParams.Email).
+		SetApiKey(e.Config.EntrezParams.ApiKey).
+		SetToolName(e.Config.EntrezParams.ToolName).
+		SetMaxResponseSize(e.Config.Runtime.MaxResponseSize)
+}
+
+// canPost 判断查询是否可以使用 epost 代替 esearch
+// epost 无法应用过滤条件及日期范围, 设置了 filters 或日期范围时仍使用 esearch
+func (e *EpostExecutor) canPost(query *types.Query) bool {
+	return len(query.IDs) > 0 && e.Config.EntrezParams.Filters == "" && !e.Config.EntrezParams.HasDateRange()
+}
+
+// executePost 执行 EPost 操作, 结果以 esearch 结果的形式发送给 esummary
+func (e *EpostExecutor) executePost(ctx context.Context, query *types.Query, searchChan chan<- *types.ESearchResult, collector *types.QueryResult) {
+	searchResult, err := e.post(ctx, query)
+
+	// 与 esearch 一致, epost 失败意味着整个查询失败
+	if err != nil || searchResult == nil {
+		logcdl.Error("execute epost failed after all retries for query '%v'", query)
+
+		collector.SetStatusOnError(err, false)
+
+		searchChan <- nil // 发送 nil，防止 esummary 协程卡住
+
+		return
+	}
+
+	collector.SetTotalRecords(searchResult.Count) // 记录总记录数
+
+	select {
+	case searchChan <- searchResult:
+		logcdl.Info("epost result for query '%v': id count: %d, WebEnv: '%s', QueryKey: '%s'",
+			query, searchResult.Count, searchResult.WebEnv, searchResult.QueryKey)
+	case <-ctx.Done():
+		err := customerrors.NewTimeoutError(
+			fmt.Sprintf("epost request timed out after %v for '%v'",
+				e.Config.Runtime.QueryTimeout, query),
+			ctx.Err())
+		collector.SetStatusOnError(err, false)
+	}
+}
+
+// post 执行 EPost 操作并有重试机制, 将结果转换为 esearch 结果
+func (e *EpostExecutor) post(ctx context.Context, query *types.Query) (*types.ESearchResult, error) {
+	config := retry.DefaultConfig()
+	result, err := retry.DoWithRetry(ctx, fmt.Sprintf("epost query for '%v'", query), config, func() (*types.EPostResult, error) {
+		postResult, err := e.Epost.Execute(ctx, query.IDs, query)
+		if err != nil {
+			return nil, err
+		}
+
+		if postResult == nil || postResult.WebEnv == "" || postResult.QueryKey == "" {
+			return nil, customerrors.NewEmptyResultError(fmt.Sprintf("epost returned no WebEnv or QueryKey for query '%v'", query))
+		}
+
+		return postResult, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	searchResult := &types.ESearchResult{
+		WebEnv:   result.WebEnv,
+		QueryKey: result.QueryKey,
+	}
+	searchResult.IdList.Id = validIDs(query.IDs, result.InvalidIdList)
+	searchResult.Count = len(searchResult.IdList.Id)
+
+	if len(result.InvalidIdList) > 0 {
+		logcdl.Warn("epost ignored %d invalid ids for query '%v': %v", len(result.InvalidIdList), query, result.InvalidIdList)
+	}
+
+	return searchResult, nil
+}
+
+// validIDs 返回 ids 中不在 invalid 中的 UID
+func validIDs(ids, invalid []string) []string {
+	if len(invalid) == 0 {
+		return ids
+	}
+
+	skip := make(map[string]struct{}, len(invalid))
+	for _, id := range invalid {
+		skip[id] = struct{}{}
+	}
+
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := skip[id]; !ok {
+			valid = append(valid, id)
+		}
+	}
+	return valid
+}
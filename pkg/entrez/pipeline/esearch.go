@@ -32,30 +32,23 @@ func NewEsearchExecutor(config *config.Config, httpClient *customeHttp.Client, r
 func (e *EsearchExecutor) setupOperations() {
 	// 配置 eSearch 查询参数
 	e.Esearch.SetQueryFilters(e.Config.EntrezParams.Filters).
+		SetDateType(e.Config.EntrezParams.DateType).
+		SetDateRange(e.Config.EntrezParams.MinDate, e.Config.EntrezParams.MaxDate).
+		SetRelDate(e.Config.EntrezParams.RelDate).
+		SetSort(e.Config.EntrezParams.Sort).
 		SetDB(e.Config.EntrezParams.DB).
 		SetRetMax(e.Config.EntrezParams.RetMax).
 		SetRetMode(e.Config.EntrezParams.RetMode.String()).
 		SetUseHistory(e.Config.EntrezParams.UseHistory).
 		SetEmail(e.Config.EntrezParams.Email).
 		SetApiKey(e.Config.EntrezParams.ApiKey).
-		SetToolName(e.Config.EntrezParams.ToolName)
+		SetToolName(e.Config.EntrezParams.ToolName).
+		SetMaxResponseSize(e.Config.Runtime.MaxResponseSize)
 }
 
-// executeSearch 执行 ESearch 操作并有重试机制
+// executeSearch 执行 ESearch 操作并将结果发送给 esummary
 func (e *EsearchExecutor) executeSearch(ctx context.Context, query *types.Query, searchChan chan<- *types.ESearchResult, collector *types.QueryResult) {
-	config := retry.DefaultConfig()
-	result, err := retry.DoWithRetry(ctx, fmt.Sprintf("esearch query for '%v'", query), config, func() (*types.ESearchResult, error) {
-		searchResult, err := e.Esearch.Execute(ctx, query)
-		if err != nil {
-			return nil, err // 这里直接返回错误，错误由 Retry 机制处理
-		}
-
-		if searchResult == nil || searchResult.Count == 0 {
-			return nil, customerrors.NewEmptyResultError(fmt.Sprintf("no records found for query '%v'", query))
-		}
-
-		return searchResult, nil
-	})
+	result, err := e.search(ctx, query)
 
 	// 如果所有重试都失败或结果为空，则直接返回
 	if err != nil || result == nil {
@@ -84,3 +77,20 @@ func (e *EsearchExecutor) executeSearch(ctx context.Context, query *types.Query,
 		collector.SetStatusOnError(err, e.Config.EntrezParams.Filters != "")
 	}
 }
+
+// search 执行 ESearch 操作并有重试机制
+func (e *EsearchExecutor) search(ctx context.Context, query *types.Query) (*types.ESearchResult, error) {
+	config := retry.DefaultConfig()
+	return retry.DoWithRetry(ctx, fmt.Sprintf("esearch query for '%v'", query), config, func() (*types.ESearchResult, error) {
+		searchResult, err := e.Esearch.Execute(ctx, query)
+		if err != nil {
+			return nil, err // 这里直接返回错误，错误由 Retry 机制处理
+		}
+
+		if searchResult == nil || searchResult.Count == 0 {
+			return nil, customerrors.NewEmptyResultError(fmt.Sprintf("no records found for query '%v'", query))
+		}
+
+		return searchResult, nil
+	})
+}
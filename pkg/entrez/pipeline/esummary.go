@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/cache"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
 	customHttp "github.com/iEchoxu/clinvarDL/pkg/entrez/http"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
@@ -18,8 +19,13 @@ import (
 )
 
 type EsummaryExecutor struct {
-	Esummary *service.ESummaryOperation
-	Config   *config.Config
+	Esummary  *service.ESummaryOperation
+	Config    *config.Config
+	enrichers []Enricher       // esummary 之后的可选补充阶段
+	research  researchFunc     // 历史会话过期时重新获取 WebEnv 及 query_key
+	listUIDs  uidListFunc      // 核对缺失记录时获取完整的 UID 列表
+	sink      ResultSink       // 批次结果的接收者, 为 nil 时结果只在查询完成后返回
+	batches   cache.BatchCache // 已完成批次的缓存, 为 nil 时不缓存批次
 }
 
 func NewEsummaryExecutor(config *config.Config, httpClient *customHttp.Client, rateLimiter *customHttp.RateLimiter) *EsummaryExecutor {
@@ -40,7 +46,8 @@ func (e *EsummaryExecutor) setupOperations() {
 		SetEmail(e.Config.EntrezParams.Email).
 		SetApiKey(e.Config.EntrezParams.ApiKey).
 		SetToolName(e.Config.EntrezParams.ToolName).
-		SetUseStream(e.Config.Stream.GetEnabled())
+		SetUseStream(e.Config.Stream.GetEnabled()).
+		SetMaxResponseSize(e.Config.Runtime.MaxResponseSize)
 }
 
 // ProcessSummaryFlow 执行 ESummary 流程
@@ -105,42 +112,76 @@ func (e *EsummaryExecutor) waitForSearchResult(ctx context.Context, query *types
 
 // processSearchResult 处理搜索结果
 func (e *EsummaryExecutor) processSearchResult(ctx context.Context, query *types.Query, searchResult *types.ESearchResult, collector *types.QueryResult) (*types.ESummaryResult, error) {
+	session := newHistorySession(searchResult, e.research, query)
+
+	var result *types.ESummaryResult
+	var err error
 	if searchResult.Count > e.Config.Runtime.BatchSize {
-		return e.executeSummaryBatches(ctx, query, searchResult, collector)
+		result, err = e.executeSummaryBatches(ctx, query, session, collector)
+	} else {
+		result, err = e.executeSingleSummary(ctx, query, session, collector)
+	}
+
+	// 记录缺失时按 UID 核对并补齐
+	if err == nil {
+		e.reconcile(ctx, query, session, result, collector)
 	}
 
-	return e.executeSingleSummary(ctx, query, searchResult, collector)
+	return result, err
 }
 
 // executeSingleSummary 执行单次 ESummary 请求
-func (e *EsummaryExecutor) executeSingleSummary(ctx context.Context, query *types.Query, searchResult *types.ESearchResult, collector *types.QueryResult) (*types.ESummaryResult, error) {
+func (e *EsummaryExecutor) executeSingleSummary(ctx context.Context, query *types.Query, session *historySession, collector *types.QueryResult) (*types.ESummaryResult, error) {
+	searchResult := session.current()
 	logcdl.Info("using single request for query '%v' with %d records", query, searchResult.Count)
 
 	collector.SetTotalBatches(1)
 
-	config := retry.DefaultConfig()
-	result, err := retry.DoWithRetry(ctx, fmt.Sprintf("single esummary request for query: '%v'", query), config, func() (*types.ESummaryResult, error) {
-		result, err := e.executeSummary(ctx, searchResult.WebEnv, searchResult.QueryKey, 0, searchResult.Count, query)
+	info := types.BatchInfo{BatchNum: 1, Start: 0, Size: searchResult.Count}
+	result := e.cachedBatch(query, searchResult.Count, info)
+	if result != nil {
+		logcdl.Info("loaded single esummary result from cache for query '%v'", query)
+	} else {
+		config := retry.DefaultConfig()
+		var err error
+		result, err = retry.DoWithRetry(ctx, fmt.Sprintf("single esummary request for query: '%v'", query), config, func() (*types.ESummaryResult, error) {
+			current := session.current()
+			result, err := e.executeSummary(ctx, current.WebEnv, current.QueryKey, 0, searchResult.Count, query)
+			if err != nil {
+				return nil, session.renewOnExpired(ctx, current, err)
+			}
+
+			if result == nil || result.DocumentSummarySet.DocumentSummary == nil {
+				return nil, customerrors.NewEmptyResultError("empty result from single esummary request")
+			}
+
+			return result, nil
+		})
+
+		// 所有重试失败后，记录失败的批次
 		if err != nil {
+			// 这里不添加进失败批次, 因为 单次请求模式下，失败就意味着整个查询失败，和 esearch 失败时的处理一样
+			// 且不会生成对应的缓存，如果没有缓存则在下次执行相同查询时会发起新请求
+			collector.SetStatusOnError(err, e.Config.EntrezParams.Filters != "")
+
+			logcdl.Error("single esummary request failed after all retries for query '%v'", query)
 			return nil, err
 		}
 
-		if result == nil || result.DocumentSummarySet.DocumentSummary == nil {
-			return nil, customerrors.NewEmptyResultError("empty result from single esummary request")
-		}
+		// 执行补充阶段
+		e.enrich(ctx, session.current(), 0, searchResult.Count, result, query)
 
-		return result, nil
-	})
+		// 与分批请求相同, 结果作为一个批次缓存
+		e.saveBatch(query, searchResult.Count, info, result)
+	}
 
-	// 所有重试失败后，记录失败的批次
-	if err != nil {
-		// 这里不添加进失败批次, 因为 单次请求模式下，失败就意味着整个查询失败，和 esearch 失败时的处理一样
-		// 且不会生成对应的缓存，如果没有缓存则在下次执行相同查询时会发起新请求
+	// 发送给写入端
+	if err := e.emit(ctx, result); err != nil {
 		collector.SetStatusOnError(err, e.Config.EntrezParams.Filters != "")
-
-		logcdl.Error("single esummary request failed after all retries for query '%v'", query)
+		logcdl.Error("failed to send single esummary result for query '%v': %v", query, err)
 		return nil, err
 	}
+	result = e.retained(result)
 
 	recordCount := len(result.DocumentSummarySet.DocumentSummary)
 
@@ -160,8 +201,8 @@ func (e *EsummaryExecutor) executeSingleSummary(ctx context.Context, query *type
 }
 
 // executeSummaryBatches 使用 retstart 分批执行 ESummary 请求
-func (e *EsummaryExecutor) executeSummaryBatches(ctx context.Context, query *types.Query, searchResult *types.ESearchResult, collector *types.QueryResult) (*types.ESummaryResult, error) {
-	totalCount := searchResult.Count
+func (e *EsummaryExecutor) executeSummaryBatches(ctx context.Context, query *types.Query, session *historySession, collector *types.QueryResult) (*types.ESummaryResult, error) {
+	totalCount := session.current().Count
 	batchSize := e.Config.Runtime.BatchSize
 
 	batch := types.NewBatch(totalCount, batchSize)
@@ -187,7 +228,7 @@ func (e *EsummaryExecutor) executeSummaryBatches(ctx context.Context, query *typ
 		wg.Add(1)
 		go func(info types.BatchInfo) {
 			defer wg.Done()
-			e.processBatch(ctx, info, searchResult, query, resultChan, semaphore, collector)
+			e.processBatch(ctx, info, session, query, resultChan, semaphore, collector)
 		}(info)
 	}
 
@@ -201,62 +242,45 @@ func (e *EsummaryExecutor) executeSummaryBatches(ctx context.Context, query *typ
 	return e.collectResults(ctx, query, resultChan, collector)
 }
 
-// processBatch 处理单个批次
-func (e *EsummaryExecutor) processBatch(ctx context.Context, info types.BatchInfo, searchResult *types.ESearchResult, query *types.Query, resultChan chan<- *types.ESummaryResult, semaphore chan struct{}, collector *types.QueryResult) {
-	select {
-	case semaphore <- struct{}{}:
-		defer func() { <-semaphore }()
-	case <-ctx.Done():
-		err := customerrors.NewTimeoutError(
-			fmt.Sprintf("esummary request timed out after %v for '%v'",
-				e.Config.Runtime.QueryTimeout, query),
-			ctx.Err())
-		collector.SetStatusOnError(err, e.Config.EntrezParams.Filters != "")
-		return
-	}
-
-	logcdl.Info("processing batch %d/%d (start=%d, size=%d) for esummary query '%v'",
-		info.BatchNum, collector.TotalBatches, info.Start, info.Size, query)
-
-	config := retry.DefaultConfig()
-	result, err := retry.DoWithRetry(ctx, fmt.Sprintf("esummary batch %d/%d (start=%d) for query '%v'", info.BatchNum, collector.TotalBatches, info.Start, query), config, func() (*types.ESummaryResult, error) {
-		result, err := e.executeSummary(ctx, searchResult.WebEnv, searchResult.QueryKey, info.Start, info.Size, query)
-		if err != nil {
-			return nil, err
-		}
-
-		// 空结果检查
-		if result == nil || result.DocumentSummarySet.DocumentSummary == nil || len(result.DocumentSummarySet.DocumentSummary) == 0 {
-			return nil, customerrors.NewEmptyResultError("server returned empty result for query")
+// processBatch 处理单个批次, 已缓存的批次不再请求
+func (e *EsummaryExecutor) processBatch(ctx context.Context, info types.BatchInfo, session *historySession, query *types.Query, resultChan chan<- *types.ESummaryResult, semaphore chan struct{}, collector *types.QueryResult) {
+	count := session.current().Count
+	result := e.cachedBatch(query, count, info)
+	if result != nil {
+		logcdl.Info("loaded esummary batch %d/%d (start=%d) from cache for query '%v'",
+			info.BatchNum, collector.TotalBatches, info.Start, query)
+	} else {
+		var err error
+		if result, err = e.fetchBatch(ctx, info, session, query, semaphore, collector); err != nil {
+			// 记录失败批次
+			// 不记录错误，因为单个批次失败不意味着整个查询失败
+			collector.AddFailedBatches(types.BatchInfo{
+				BatchNum: info.BatchNum,
+				Start:    info.Start,
+				Size:     info.Size,
+				ErrMsg:   err.Error(),
+			})
+			return
 		}
 
-		return result, nil
-	})
-
-	// 如果达到最大重试次数，记录该批次的信息
-	if err != nil {
-		logcdl.Error("esummary batch %d/%d (start=%d) failed after all retries for query '%v'",
-			info.BatchNum, collector.TotalBatches, info.Start, query)
+		// 批次完成后立即缓存, 查询中断后再次执行时不再请求
+		e.saveBatch(query, count, info, result)
+	}
 
-		// 记录失败批次
-		// 不记录错误，因为单个批次失败不意味着整个查询失败
+	// 发送给写入端, 写入端停止接收时记录为失败批次
+	if err := e.emit(ctx, result); err != nil {
 		collector.AddFailedBatches(types.BatchInfo{
 			BatchNum: info.BatchNum,
 			Start:    info.Start,
 			Size:     info.Size,
 			ErrMsg:   err.Error(),
 		})
-
 		return
 	}
+	result = e.retained(result)
 
 	select {
 	case resultChan <- result:
-		// 只有在重试模式下需要移除失败批次
-		if len(collector.FailedBatches) > 0 {
-			collector.RemoveFailedBatch(info.Start)
-		}
-
 		recordCount := len(result.DocumentSummarySet.DocumentSummary)
 
 		// 验证每个批次返回的记录数是否符预期(与 retmax 较)
@@ -279,6 +303,50 @@ func (e *EsummaryExecutor) processBatch(ctx context.Context, info types.BatchInf
 	}
 }
 
+// fetchBatch 请求单个批次并执行补充阶段
+func (e *EsummaryExecutor) fetchBatch(ctx context.Context, info types.BatchInfo, session *historySession, query *types.Query, semaphore chan struct{}, collector *types.QueryResult) (*types.ESummaryResult, error) {
+	select {
+	case semaphore <- struct{}{}:
+		defer func() { <-semaphore }()
+	case <-ctx.Done():
+		return nil, customerrors.NewTimeoutError(
+			fmt.Sprintf("esummary request timed out after %v for '%v'",
+				e.Config.Runtime.QueryTimeout, query),
+			ctx.Err())
+	}
+
+	logcdl.Info("processing batch %d/%d (start=%d, size=%d) for esummary query '%v'",
+		info.BatchNum, collector.TotalBatches, info.Start, info.Size, query)
+
+	config := retry.DefaultConfig()
+	result, err := retry.DoWithRetry(ctx, fmt.Sprintf("esummary batch %d/%d (start=%d) for query '%v'", info.BatchNum, collector.TotalBatches, info.Start, query), config, func() (*types.ESummaryResult, error) {
+		current := session.current()
+		result, err := e.executeSummary(ctx, current.WebEnv, current.QueryKey, info.Start, info.Size, query)
+		if err != nil {
+			return nil, session.renewOnExpired(ctx, current, err)
+		}
+
+		// 空结果检查
+		if result == nil || result.DocumentSummarySet.DocumentSummary == nil || len(result.DocumentSummarySet.DocumentSummary) == 0 {
+			return nil, customerrors.NewEmptyResultError("server returned empty result for query")
+		}
+
+		return result, nil
+	})
+
+	// 如果达到最大重试次数，返回错误由调用方记录该批次的信息
+	if err != nil {
+		logcdl.Error("esummary batch %d/%d (start=%d) failed after all retries for query '%v'",
+			info.BatchNum, collector.TotalBatches, info.Start, query)
+		return nil, err
+	}
+
+	// 执行补充阶段
+	e.enrich(ctx, session.current(), info.Start, info.Size, result, query)
+
+	return result, nil
+}
+
 // collectResults 收集所有批次的结果
 func (e *EsummaryExecutor) collectResults(ctx context.Context, query *types.Query, resultChan <-chan *types.ESummaryResult, collector *types.QueryResult) (*types.ESummaryResult, error) {
 	// 完整初始化结构体
@@ -342,70 +410,26 @@ func (e *EsummaryExecutor) executeSummary(ctx context.Context, webEnv, queryKey
 		return nil, ctx.Err()
 	}
 
-	// 设置分页参数
-	e.Esummary.Parameters.Set("retstart", fmt.Sprintf("%d", start))
-	e.Esummary.Parameters.Set("retmax", fmt.Sprintf("%d", batchSize))
-
-	// 执 ESummary 请求
-	result, err := e.Esummary.Execute(ctx, webEnv, queryKey, query)
+	// 执行 ESummary 请求, 分页参数由每个请求单独设置
+	result, err := e.Esummary.Execute(ctx, webEnv, queryKey, start, batchSize, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return dropFailedDocs(result, query), nil
 }
 
-// RetryBatches 重试多个批次的 ESummary 请求
-func (e *EsummaryExecutor) RetryBatches(ctx context.Context, query *types.Query, batches []types.BatchInfo, searchChan <-chan *types.ESearchResult, collector *types.QueryResult) {
-	searchResult, err := e.waitForSearchResult(ctx, query, searchChan)
-	if err != nil {
-		var emptyError *customerrors.EmptyResultError
-		if !errors.As(err, &emptyError) {
-			collector.SetStatusOnError(err, e.Config.EntrezParams.Filters != "")
-		}
-		return
-	}
-
-	resultChan := make(chan *types.ESummaryResult, len(batches))
-
-	// 获取 ESummary 并发数
-	maxBatchWorkers := e.Config.Runtime.GetEsummaryWorkers()
-	esummaryWorkers := utils.GetWorkerCount(maxBatchWorkers, len(batches))
-
-	// 创建工作池
-	semaphore := make(chan struct{}, esummaryWorkers)
-	var wg sync.WaitGroup
-
-	// 并发处理每个批次
-	for _, batch := range batches {
-		wg.Add(1)
-		go func(batch types.BatchInfo) {
-			defer wg.Done()
-			e.processBatch(ctx, batch, searchResult, query, resultChan, semaphore, collector)
-		}(batch)
-	}
-
-	// 等待所有批次处理完成
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	// 收集结果
-	result, err := e.collectResults(ctx, query, resultChan, collector)
-	if err != nil {
-		logcdl.Error("failed to collect some results: %v", err)
-
-		if result == nil {
-			collector.SetStatusOnError(err, e.Config.EntrezParams.Filters != "")
-			return
+// dropFailedDocs 移除无法获取摘要的 UID (如 epost 上传的 UID 在数据库中不存在)
+func dropFailedDocs(result *types.ESummaryResult, query *types.Query) *types.ESummaryResult {
+	docs := result.DocumentSummarySet.DocumentSummary[:0]
+	for _, doc := range result.DocumentSummarySet.DocumentSummary {
+		if doc.Error != "" {
+			logcdl.Warn("esummary skipped uid %s for query '%v': %s", doc.Uid, query, doc.Error)
+			continue
 		}
+		docs = append(docs, doc)
 	}
+	result.DocumentSummarySet.DocumentSummary = docs
 
-	// 合并新的结果到现有结果中
-	if result != nil && result.DocumentSummarySet.DocumentSummary != nil {
-		collector.Result.DocumentSummarySet.DocumentSummary = append(
-			collector.Result.DocumentSummarySet.DocumentSummary,
-			result.DocumentSummarySet.DocumentSummary...)
-	}
+	return result
 }
@@ -0,0 +1,249 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	customHttp "github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+func TestMain(m *testing.M) {
+	// 日志目录相对于当前目录, 写入临时目录以免在源码目录中生成日志文件
+	dir, err := os.MkdirTemp("", "clinvarDL-pipeline-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cwd, _ := os.Getwd()
+	logDir, err := filepath.Rel(cwd, dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := logcdl.InitLogger(logcdl.Options{
+		MinLevel:    logcdl.ERROR,
+		LogDir:      logDir,
+		LogFileName: "clinvardl_%s.log",
+		TimeFormat:  "2006-01-02",
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	logcdl.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// window 单次 esummary 请求的分页参数
+type window struct {
+	start int
+	size  int
+}
+
+// summaryServer 模拟 esummary 接口, 记录每个请求的 retstart 及 retmax
+// 每个请求延迟返回, 使多个批次的请求同时进行
+type summaryServer struct {
+	total int
+
+	mu       sync.Mutex
+	windows  []window
+	inFlight int
+	peak     int // 同时进行的请求数的最大值
+}
+
+func (s *summaryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start, err := strconv.Atoi(r.URL.Query().Get("retstart"))
+	if err != nil {
+		http.Error(w, "invalid retstart", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.Atoi(r.URL.Query().Get("retmax"))
+	if err != nil {
+		http.Error(w, "invalid retmax", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.windows = append(s.windows, window{start: start, size: size})
+	s.inFlight++
+	if s.inFlight > s.peak {
+		s.peak = s.inFlight
+	}
+	s.mu.Unlock()
+
+	time.Sleep(150 * time.Millisecond)
+
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><DocumentSummarySet status="OK">`)
+	for i := start; i < start+size && i < s.total; i++ {
+		fmt.Fprintf(w, `<DocumentSummary uid="%d"><accession>VCV%09d</accession></DocumentSummary>`, i+1, i+1)
+	}
+	fmt.Fprint(w, `</DocumentSummarySet></eSummaryResult>`)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+}
+
+// TestSummaryBatchWindows 并发批次各自请求分配的 [retstart, retstart+retmax) 区间, 区间不重复也不重叠
+// 需要配合 -race 运行, 检查批次之间是否共用请求参数
+func TestSummaryBatchWindows(t *testing.T) {
+	const (
+		total     = 23
+		batchSize = 5
+	)
+
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			server := &summaryServer{total: total}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			cfg := config.NewConfig("clinvar").
+				SetRetMode(config.RetModeXML).
+				SetApiKey("test").
+				SetStreamEnabled(stream).
+				SetReconcile(false)
+			cfg.Runtime.BatchSize = batchSize
+			cfg.Runtime.MaxEsummaryWorkers = 4
+
+			executor := NewEsummaryExecutor(cfg, customHttp.NewClient(customHttp.DefaultHTTPConfig()), customHttp.NewRateLimiter(true))
+			executor.Esummary.BaseURL = srv.URL
+
+			query := types.NewQuery("BRCA1[gene]")
+			collector := types.NewQueryResult(query.GetQueryID(), query.Content)
+			collector.SetTotalRecords(total)
+
+			searchChan := make(chan *types.ESearchResult, 1)
+			searchChan <- &types.ESearchResult{Count: total, QueryKey: "1", WebEnv: "MCID_test"}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			executor.ProcessSummaryFlow(ctx, query, searchChan, collector)
+
+			batches := types.NewBatch(total, batchSize)
+			expected := make(map[window]int, batches.Batches)
+			for _, info := range batches.BatchInfos {
+				expected[window{start: info.Start, size: info.Size}] = 0
+			}
+
+			server.mu.Lock()
+			windows := append([]window(nil), server.windows...)
+			peak := server.peak
+			server.mu.Unlock()
+
+			covered := make([]int, total)
+			for _, w := range windows {
+				if _, ok := expected[w]; !ok {
+					t.Errorf("unexpected window retstart=%d retmax=%d", w.start, w.size)
+					continue
+				}
+				expected[w]++
+				for i := w.start; i < w.start+w.size; i++ {
+					covered[i]++
+				}
+			}
+			for w, n := range expected {
+				if n != 1 {
+					t.Errorf("window retstart=%d retmax=%d requested %d times, want 1", w.start, w.size, n)
+				}
+			}
+			for i, n := range covered {
+				if n > 1 {
+					t.Errorf("record %d requested by %d overlapping windows", i, n)
+				}
+			}
+			if peak < 2 {
+				t.Errorf("batches did not run concurrently (peak in-flight requests: %d)", peak)
+			}
+
+			if collector.Result == nil {
+				t.Fatalf("no esummary result, error: %v", collector.Error)
+			}
+			uids := make(map[string]struct{}, total)
+			for _, doc := range collector.Result.DocumentSummarySet.DocumentSummary {
+				if _, ok := uids[doc.Uid]; ok {
+					t.Errorf("uid %s returned more than once", doc.Uid)
+				}
+				uids[doc.Uid] = struct{}{}
+			}
+			if len(uids) != total {
+				t.Errorf("got %d unique records, want %d", len(uids), total)
+			}
+		})
+	}
+}
+
+// TestFailedBatchKeepsNCBIMessage 响应体中的错误不重试时, 失败批次原样记录 NCBI 返回的错误信息
+func TestFailedBatchKeepsNCBIMessage(t *testing.T) {
+	const (
+		total     = 10
+		batchSize = 5
+		message   = "Invalid uid 99999999999 at position=0"
+	)
+
+	var mu sync.Mutex
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("retstart"))
+		if start == batchSize {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><ERROR>%s</ERROR></eSummaryResult>`, message)
+			return
+		}
+
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><DocumentSummarySet status="OK">`)
+		for i := start; i < start+batchSize; i++ {
+			fmt.Fprintf(w, `<DocumentSummary uid="%d"><accession>VCV%09d</accession></DocumentSummary>`, i+1, i+1)
+		}
+		fmt.Fprint(w, `</DocumentSummarySet></eSummaryResult>`)
+	}))
+	defer srv.Close()
+
+	cfg := config.NewConfig("clinvar").
+		SetRetMode(config.RetModeXML).
+		SetApiKey("test").
+		SetStreamEnabled(false).
+		SetReconcile(false)
+	cfg.Runtime.BatchSize = batchSize
+
+	executor := NewEsummaryExecutor(cfg, customHttp.NewClient(customHttp.DefaultHTTPConfig()), customHttp.NewRateLimiter(true))
+	executor.Esummary.BaseURL = srv.URL
+
+	query := types.NewQuery("BRCA1[gene]")
+	collector := types.NewQueryResult(query.GetQueryID(), query.Content)
+	collector.SetTotalRecords(total)
+
+	searchChan := make(chan *types.ESearchResult, 1)
+	searchChan <- &types.ESearchResult{Count: total, QueryKey: "1", WebEnv: "MCID_test"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	executor.ProcessSummaryFlow(ctx, query, searchChan, collector)
+
+	if len(collector.FailedBatches) != 1 {
+		t.Fatalf("got %d failed batches, want 1", len(collector.FailedBatches))
+	}
+	if got := collector.FailedBatches[0].ErrMsg; got != message {
+		t.Errorf("failed batch message = %q, want %q", got, message)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 {
+		t.Errorf("invalid uid error requested %d times, want no retry", requests)
+	}
+}
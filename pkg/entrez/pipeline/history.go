@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/pkg/errors"
+)
+
+// researchFunc 重新执行 esearch (或 epost) 获取新的 WebEnv 及 query_key
+type researchFunc func(ctx context.Context, query *types.Query) (*types.ESearchResult, error)
+
+// historySession 保存 esummary 各批次共用的 WebEnv 及 query_key
+// 下载时间过长导致 NCBI 历史会话过期时, 在锁内重新执行一次 esearch, 剩余批次使用新的 WebEnv 及 query_key
+type historySession struct {
+	mu       sync.RWMutex
+	result   *types.ESearchResult
+	renewed  bool // 每个会话只重新执行一次 esearch
+	research researchFunc
+	query    *types.Query
+}
+
+func newHistorySession(result *types.ESearchResult, research researchFunc, query *types.Query) *historySession {
+	return &historySession{
+		result:   result,
+		research: research,
+		query:    query,
+	}
+}
+
+// current 返回当前使用的 esearch 结果, 返回值不会被修改
+func (s *historySession) current() *types.ESearchResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.result
+}
+
+// renewOnExpired 在 err 为历史会话过期错误时替换 stale 对应的 WebEnv 及 query_key
+// 替换成功 (或已被其他批次替换) 时返回可重试的错误, 使批次使用新的 WebEnv 重试; 否则返回不可重试的错误, 避免在失效的会话上耗尽重试次数
+func (s *historySession) renewOnExpired(ctx context.Context, stale *types.ESearchResult, err error) error {
+	var expired *customerrors.HistoryExpiredError
+	if !errors.As(err, &expired) {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 其他批次已经替换了 WebEnv
+	if s.result != stale {
+		return expired.Renewed()
+	}
+
+	if s.renewed || s.research == nil {
+		return errors.WithMessagef(err, "history session for query '%v' expired again after renewal", s.query)
+	}
+	s.renewed = true
+
+	logcdl.Warn("history session expired for query '%v' (WebEnv: '%s'), re-running esearch", s.query, stale.WebEnv)
+
+	result, researchErr := s.research(ctx, s.query)
+	if researchErr != nil {
+		return errors.WithMessagef(err, "failed to renew history session for query '%v': %v", s.query, researchErr)
+	}
+
+	// 批次按原记录数划分, 记录数变化时部分记录可能遗漏或重复
+	if result.Count != stale.Count {
+		logcdl.Warn("record count changed after renewing history session for query '%v': %d -> %d, batches still follow the original count",
+			s.query, stale.Count, result.Count)
+	}
+
+	s.result = result
+	logcdl.Info("renewed history session for query '%v': WebEnv: '%s', QueryKey: '%s'", s.query, result.WebEnv, result.QueryKey)
+
+	return expired.Renewed()
+}
@@ -14,16 +14,39 @@ import (
 
 type Pipeline struct {
 	Search  *EsearchExecutor
+	Post    *EpostExecutor
 	Summary *EsummaryExecutor
 	Config  *config.Config
 }
 
-func NewPipeline(config *config.Config, httpClient *customHttp.Client, rateLimiter *customHttp.RateLimiter) *Pipeline {
-	return &Pipeline{
+func NewPipeline(config *config.Config, httpClient *customHttp.Client, rateLimiter *customHttp.RateLimiter, opts ...PipelineOption) *Pipeline {
+	summary := NewEsummaryExecutor(config, httpClient, rateLimiter)
+
+	// 按配置添加 esummary 之后的补充阶段
+	if config.Enrichment != nil && config.Enrichment.FetchVCV {
+		summary.AddEnricher(NewEfetchExecutor(config, httpClient, rateLimiter))
+	}
+	if config.Enrichment != nil && len(config.Enrichment.Links) > 0 {
+		summary.AddEnricher(NewElinkExecutor(config, httpClient, rateLimiter))
+	}
+
+	p := &Pipeline{
 		Search:  NewEsearchExecutor(config, httpClient, rateLimiter),
-		Summary: NewEsummaryExecutor(config, httpClient, rateLimiter),
+		Post:    NewEpostExecutor(config, httpClient, rateLimiter),
+		Summary: summary,
 		Config:  config,
 	}
+
+	// 历史会话过期时由 esummary 重新获取 WebEnv 及 query_key
+	summary.research = p.research
+	// 核对缺失记录时由 esearch 分页获取完整的 UID 列表
+	summary.listUIDs = p.Search.uids
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // ExecuteQuery 按固定顺序执行操作管道
@@ -32,6 +55,8 @@ func (p *Pipeline) ExecuteQuery(ctx context.Context, query *types.Query) (*types
 
 	//  初始化查询统计信息
 	queryStats := types.NewQueryResult(query.GetQueryID(), query.Content)
+	queryStats.Regions = query.Regions
+	queryStats.ResolvedSymbols = query.ResolvedSymbols
 
 	// 执行新的查询
 	return p.executeQuery(ctx, query, queryStats)
@@ -45,21 +70,26 @@ func (p *Pipeline) executeQuery(ctx context.Context, query *types.Query, querySt
 
 	// 创建通道
 	searchChan := make(chan *types.ESearchResult, 1)
+	summaryChan := make(chan *types.ESearchResult, 1)
 	doneChan := make(chan struct{})
 
 	var wg sync.WaitGroup
+	var subQueries []*types.Query
 	wg.Add(2)
 
 	// 启动 eSearch 查询
 	go func() {
 		defer wg.Done()
-		p.Search.executeSearch(queryCtx, query, searchChan, queryStats)
+		p.search(queryCtx, query, searchChan, queryStats)
 	}()
 
-	// 启动 eSummary 查询
+	// 启动 eSummary 查询, 结果数量超过上限时改为拆分查询, 不在当前 WebEnv 上执行 esummary
 	go func() {
 		defer wg.Done()
-		p.Summary.ProcessSummaryFlow(queryCtx, query, searchChan, queryStats)
+		if subQueries = p.checkSplit(queryCtx, query, searchChan, summaryChan); subQueries != nil {
+			return
+		}
+		p.Summary.ProcessSummaryFlow(queryCtx, query, summaryChan, queryStats)
 	}()
 
 	// 等待所有操作完成
@@ -69,7 +99,37 @@ func (p *Pipeline) executeQuery(ctx context.Context, query *types.Query, querySt
 	}()
 
 	// 等待结果或超时
-	return p.waitForResults(queryCtx, query, queryStats, doneChan)
+	result, err := p.waitForResults(queryCtx, query, queryStats, doneChan)
+	if err != nil {
+		return result, err
+	}
+	if subQueries == nil {
+		result.BatchKeys = p.Summary.batchKeys(query)
+		return result, nil
+	}
+
+	// 子查询使用各自的超时时间
+	return p.executeSplit(ctx, query, subQueries, queryStats)
+}
+
+// search 获取 esummary 使用的 WebEnv 及 query_key
+// 查询内容为 VariationID 列表时使用 epost 上传 UID, 跳过 esearch
+func (p *Pipeline) search(ctx context.Context, query *types.Query, searchChan chan<- *types.ESearchResult, collector *types.QueryResult) {
+	if p.Post.canPost(query) {
+		p.Post.executePost(ctx, query, searchChan, collector)
+		return
+	}
+
+	p.Search.executeSearch(ctx, query, searchChan, collector)
+}
+
+// research 重新获取 WebEnv 及 query_key, 与 search 使用相同的方式
+func (p *Pipeline) research(ctx context.Context, query *types.Query) (*types.ESearchResult, error) {
+	if p.Post.canPost(query) {
+		return p.Post.post(ctx, query)
+	}
+
+	return p.Search.search(ctx, query)
 }
 
 // waitForResults 等待结果或超时
@@ -96,52 +156,3 @@ func (p *Pipeline) processQueryResults(queryStats *types.QueryResult) (*types.Qu
 
 	return queryStats, queryStats.Error
 }
-
-// RetryFailedBatches 重试失败的批次
-func (p *Pipeline) RetryFailedBatches(ctx context.Context, query *types.Query, cachedResult *types.QueryResult) *types.QueryResult {
-	// 创建新的查询上下文
-	queryCtx, cancel := context.WithTimeout(ctx, p.Config.Runtime.QueryTimeout)
-	defer cancel()
-
-	// 获取失败的批次信息
-	failedBatches := cachedResult.FailedBatches
-	if len(failedBatches) == 0 {
-		return cachedResult
-	}
-
-	// 创建通道
-	searchChan := make(chan *types.ESearchResult, 1)
-	doneChan := make(chan struct{})
-
-	// 启动查询和处理
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	// 启动 Search
-	go func() {
-		defer wg.Done()
-		p.Search.executeSearch(queryCtx, query, searchChan, cachedResult)
-	}()
-
-	// 启动 Summary
-	go func() {
-		defer wg.Done()
-		p.Summary.RetryBatches(queryCtx, query, failedBatches, searchChan, cachedResult)
-	}()
-
-	// 等待所有操作完成
-	go func() {
-		wg.Wait()
-		close(searchChan)
-		close(doneChan)
-	}()
-
-	// 使用 waitForResults 处理结果，但保留原有的错误
-	result, err := p.waitForResults(queryCtx, query, cachedResult, doneChan)
-	if err != nil {
-		// 如果发生错误，保留原有的失败批次信息
-		return cachedResult
-	}
-
-	return result
-}
@@ -0,0 +1,183 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/utils"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// defaultUIDPageSize 未设置 retmax 时分页获取 UID 列表的页大小, 与 esearch retmax 的上限一致
+const defaultUIDPageSize = 10000
+
+// uidListFunc 获取查询结果的完整 UID 列表
+type uidListFunc func(ctx context.Context, query *types.Query, searchResult *types.ESearchResult) ([]string, error)
+
+// uids 获取查询结果的完整 UID 列表
+// esearch 返回的 IdList 最多包含 retmax 个 UID, 不足时使用 retstart 分页获取剩余的 UID
+func (e *EsearchExecutor) uids(ctx context.Context, query *types.Query, searchResult *types.ESearchResult) ([]string, error) {
+	uids := append([]string(nil), searchResult.IdList.Id...)
+
+	pageSize := e.Config.EntrezParams.RetMax
+	if pageSize <= 0 {
+		pageSize = defaultUIDPageSize
+	}
+
+	for len(uids) < searchResult.Count {
+		start := len(uids)
+
+		config := retry.DefaultConfig()
+		page, err := retry.DoWithRetry(ctx, fmt.Sprintf("esearch uid list (start=%d) for query '%v'", start, query), config, func() (*types.ESearchResult, error) {
+			return e.Esearch.ExecuteIDs(ctx, query, start, pageSize)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// 核对期间记录数减少时, 已获取的 UID 即为完整列表
+		if page == nil || len(page.IdList.Id) == 0 {
+			break
+		}
+
+		uids = append(uids, page.IdList.Id...)
+	}
+
+	return uids, nil
+}
+
+// reconcile 按 UID 核对 esummary 结果, 通过 UID 补齐缺失的记录
+// 只在已获取的记录数少于 esearch 返回的记录数时执行, 补齐后仍缺失的 UID 记录在 collector.MissingUIDs 中
+func (e *EsummaryExecutor) reconcile(ctx context.Context, query *types.Query, session *historySession, result *types.ESummaryResult, collector *types.QueryResult) {
+	if collector.ProcessedCount >= collector.TotalRecords {
+		collector.SetMissingUIDs(nil)
+		return
+	}
+
+	if !e.Config.Runtime.Reconcile || e.listUIDs == nil || result == nil || ctx.Err() != nil {
+		return
+	}
+
+	uids, err := e.listUIDs(ctx, query, session.current())
+	if err != nil {
+		logcdl.Warn("failed to get uid list for reconciling query '%v': %v", query, err)
+		return
+	}
+
+	found := make(map[string]struct{}, len(result.DocumentSummarySet.DocumentSummary))
+	for _, doc := range result.DocumentSummarySet.DocumentSummary {
+		found[doc.Uid] = struct{}{}
+	}
+
+	var missing []string
+	for _, uid := range uids {
+		if _, ok := found[uid]; !ok {
+			missing = append(missing, uid)
+		}
+	}
+
+	if len(missing) == 0 {
+		logcdl.Info("no missing uids found for query '%v' (%d uids, %d records)", query, len(uids), collector.ProcessedCount)
+		return
+	}
+
+	logcdl.Info("reconciling %d missing uids for query '%v'", len(missing), query)
+
+	recovered := e.fetchUIDs(ctx, query, missing)
+
+	var unrecoverable []string
+	batch := &types.ESummaryResult{}
+	for _, uid := range missing {
+		doc, ok := recovered[uid]
+		if !ok {
+			unrecoverable = append(unrecoverable, uid)
+			continue
+		}
+		batch.DocumentSummarySet.DocumentSummary = append(batch.DocumentSummarySet.DocumentSummary, doc)
+	}
+
+	// 补齐的记录按 UID 执行补充阶段, 作为一个批次缓存并发送给写入端, retstart 取 esearch 记录数, 不与分批请求的批次重叠
+	if len(batch.DocumentSummarySet.DocumentSummary) > 0 {
+		count := session.current().Count
+		e.enrich(ctx, nil, count, len(batch.DocumentSummarySet.DocumentSummary), batch, query)
+		e.saveBatch(query, count, types.BatchInfo{Start: count, Size: len(batch.DocumentSummarySet.DocumentSummary)}, batch)
+	}
+	if err := e.emit(ctx, batch); err != nil {
+		logcdl.Error("failed to send %d recovered records for query '%v': %v", len(recovered), query, err)
+		collector.SetMissingUIDs(missing)
+		return
+	}
+
+	for _, doc := range e.retained(batch).DocumentSummarySet.DocumentSummary {
+		result.DocumentSummarySet.DocumentSummary = append(result.DocumentSummarySet.DocumentSummary, doc)
+		found[doc.Uid] = struct{}{}
+	}
+
+	collector.AddProcessedRecords(len(recovered))
+
+	// 所有 UID 均已补齐的失败批次无需再重试
+	for _, batch := range append([]types.BatchInfo(nil), collector.FailedBatches...) {
+		if batch.Start >= len(uids) {
+			continue
+		}
+
+		complete := true
+		for _, uid := range uids[batch.Start:utils.Min(batch.Start+batch.Size, len(uids))] {
+			if _, ok := found[uid]; !ok {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			collector.RemoveFailedBatch(batch.Start)
+		}
+	}
+
+	collector.SetMissingUIDs(unrecoverable)
+
+	if len(unrecoverable) > 0 {
+		logcdl.Warn("recovered %d/%d missing uids for query '%v', unrecoverable: %v",
+			len(recovered), len(missing), query, unrecoverable)
+		return
+	}
+
+	logcdl.Success("recovered all %d missing uids for query '%v'", len(missing), query)
+}
+
+// fetchUIDs 按批次通过 UID 获取文档摘要, 返回 UID 到文档摘要的映射
+// 批次失败时只打印错误, 未获取到的 UID 由调用方记录
+func (e *EsummaryExecutor) fetchUIDs(ctx context.Context, query *types.Query, uids []string) map[string]*types.DocumentSummary {
+	docs := make(map[string]*types.DocumentSummary, len(uids))
+	batchSize := e.Config.Runtime.BatchSize
+
+	for start := 0; start < len(uids); start += batchSize {
+		batch := uids[start:utils.Min(start+batchSize, len(uids))]
+
+		config := retry.DefaultConfig()
+		result, err := retry.DoWithRetry(ctx, fmt.Sprintf("esummary missing uids (start=%d) for query '%v'", start, query), config, func() (*types.ESummaryResult, error) {
+			result, err := e.Esummary.ExecuteIDs(ctx, batch, query)
+			if err != nil {
+				return nil, err
+			}
+
+			if result == nil || len(result.DocumentSummarySet.DocumentSummary) == 0 {
+				return nil, customerrors.NewEmptyResultError("server returned no summaries for missing uids")
+			}
+
+			return result, nil
+		})
+		if err != nil {
+			logcdl.Error("esummary missing uids (start=%d) failed after all retries for query '%v': %v", start, query, err)
+			continue
+		}
+
+		for _, doc := range dropFailedDocs(result, query).DocumentSummarySet.DocumentSummary {
+			docs[doc.Uid] = doc
+		}
+	}
+
+	return docs
+}
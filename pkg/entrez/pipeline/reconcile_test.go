@@ -0,0 +1,228 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
+	customHttp "github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// reconcileServer 模拟 esummary 接口, 分页请求中省略部分记录或使整个批次失败, 按 id 请求时返回省略的记录
+type reconcileServer struct {
+	total         int
+	omitted       map[int]bool // 分页请求中省略的 UID
+	failedStarts  map[int]bool // 返回 400 的批次的 retstart
+	unrecoverable map[int]bool // 按 id 请求时返回错误信息的 UID
+}
+
+func (s *reconcileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var uids []int
+	if ids := r.FormValue("id"); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			uid, err := strconv.Atoi(id)
+			if err != nil {
+				http.Error(w, "invalid id", http.StatusBadRequest)
+				return
+			}
+			uids = append(uids, uid)
+		}
+	} else {
+		start, err := strconv.Atoi(r.FormValue("retstart"))
+		if err != nil || s.failedStarts[start] {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		size, _ := strconv.Atoi(r.FormValue("retmax"))
+		for uid := start + 1; uid <= start+size && uid <= s.total; uid++ {
+			if !s.omitted[uid] && !s.unrecoverable[uid] {
+				uids = append(uids, uid)
+			}
+		}
+	}
+
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><DocumentSummarySet status="OK">`)
+	for _, uid := range uids {
+		if s.unrecoverable[uid] {
+			fmt.Fprintf(w, `<DocumentSummary uid="%d"><error>cannot get document summary</error></DocumentSummary>`, uid)
+			continue
+		}
+		fmt.Fprintf(w, `<DocumentSummary uid="%d"><accession>VCV%09d</accession></DocumentSummary>`, uid, uid)
+	}
+	fmt.Fprint(w, `</DocumentSummarySet></eSummaryResult>`)
+}
+
+// recordingEnricher 记录补充阶段收到的批次
+type recordingEnricher struct {
+	mu    sync.Mutex
+	byUID [][]string // searchResult 为 nil (按 UID 补充) 时收到的 UID
+}
+
+func (r *recordingEnricher) Name() string {
+	return "recording"
+}
+
+func (r *recordingEnricher) Enrich(_ context.Context, searchResult *types.ESearchResult, _, _ int, result *types.ESummaryResult, _ *types.Query) error {
+	if searchResult != nil {
+		return nil
+	}
+
+	uids := make([]string, 0, len(result.DocumentSummarySet.DocumentSummary))
+	for _, doc := range result.DocumentSummarySet.DocumentSummary {
+		uids = append(uids, doc.Uid)
+	}
+
+	r.mu.Lock()
+	r.byUID = append(r.byUID, uids)
+	r.mu.Unlock()
+	return nil
+}
+
+// TestReconcileMissingUIDs 核对时只按 UID 请求缺失的记录并执行补充阶段,
+// 所有 UID 均已补齐的失败批次被移除, 仍无法获取的 UID 记录在 MissingUIDs 中
+func TestReconcileMissingUIDs(t *testing.T) {
+	const (
+		total     = 20
+		batchSize = 5
+	)
+
+	server := &reconcileServer{
+		total:         total,
+		omitted:       map[int]bool{3: true},
+		failedStarts:  map[int]bool{10: true, 15: true},
+		unrecoverable: map[int]bool{18: true},
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	cfg := config.NewConfig("clinvar").
+		SetRetMode(config.RetModeXML).
+		SetApiKey("test").
+		SetStreamEnabled(false).
+		SetReconcile(true)
+	cfg.Runtime.BatchSize = batchSize
+	cfg.Runtime.MaxEsummaryWorkers = 2
+
+	enricher := &recordingEnricher{}
+	executor := NewEsummaryExecutor(cfg, customHttp.NewClient(customHttp.DefaultHTTPConfig()), customHttp.NewRateLimiter(true))
+	executor.Esummary.BaseURL = srv.URL
+	executor.AddEnricher(enricher)
+	executor.listUIDs = func(context.Context, *types.Query, *types.ESearchResult) ([]string, error) {
+		uids := make([]string, 0, total)
+		for uid := 1; uid <= total; uid++ {
+			uids = append(uids, strconv.Itoa(uid))
+		}
+		return uids, nil
+	}
+
+	query := types.NewQuery("BRCA1[gene]")
+	collector := types.NewQueryResult(query.GetQueryID(), query.Content)
+	collector.SetTotalRecords(total)
+
+	searchChan := make(chan *types.ESearchResult, 1)
+	searchChan <- &types.ESearchResult{Count: total, QueryKey: "1", WebEnv: "MCID_test"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	executor.ProcessSummaryFlow(ctx, query, searchChan, collector)
+
+	if collector.Result == nil {
+		t.Fatalf("no esummary result, error: %v", collector.Error)
+	}
+
+	var uids []int
+	for _, doc := range collector.Result.DocumentSummarySet.DocumentSummary {
+		uid, _ := strconv.Atoi(doc.Uid)
+		uids = append(uids, uid)
+	}
+	sort.Ints(uids)
+	var want []int
+	for uid := 1; uid <= total; uid++ {
+		if uid != 18 {
+			want = append(want, uid)
+		}
+	}
+	if !reflect.DeepEqual(uids, want) {
+		t.Errorf("got uids %v, want %v", uids, want)
+	}
+	if collector.ProcessedCount != len(want) {
+		t.Errorf("processed %d records, want %d", collector.ProcessedCount, len(want))
+	}
+
+	if !reflect.DeepEqual(collector.MissingUIDs, []string{"18"}) {
+		t.Errorf("got missing uids %v, want [18]", collector.MissingUIDs)
+	}
+
+	// start=10 的批次已全部补齐, start=15 的批次仍缺少 18
+	var failed []int
+	for _, batch := range collector.FailedBatches {
+		failed = append(failed, batch.Start)
+	}
+	if !reflect.DeepEqual(failed, []int{15}) {
+		t.Errorf("got failed batches %v, want [15]", failed)
+	}
+
+	enricher.mu.Lock()
+	defer enricher.mu.Unlock()
+	wantEnriched := [][]string{{"3", "11", "12", "13", "14", "15", "16", "17", "19", "20"}}
+	if !reflect.DeepEqual(enricher.byUID, wantEnriched) {
+		t.Errorf("recovered records enriched by uid %v, want %v", enricher.byUID, wantEnriched)
+	}
+}
+
+// TestEfetchEnrichByUID searchResult 为 nil 时 efetch 按 VariationID 分批请求, 不使用 WebEnv
+func TestEfetchEnrichByUID(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("WebEnv") != "" || r.FormValue("id") == "" {
+			http.Error(w, "expected id list", http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		requests = append(requests, r.FormValue("id"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8" ?><ClinVarResult-Set>`)
+		for _, id := range strings.Split(r.FormValue("id"), ",") {
+			fmt.Fprintf(w, `<VariationArchive VariationID="%s" Accession="VCV%09s" Version="1"><ClassifiedRecord></ClassifiedRecord></VariationArchive>`, id, id)
+		}
+		fmt.Fprint(w, `</ClinVarResult-Set>`)
+	}))
+	defer srv.Close()
+
+	cfg := config.NewConfig("clinvar").SetApiKey("test").SetFetchVCV(true)
+	cfg.Enrichment.FetchBatchSize = 2
+
+	executor := NewEfetchExecutor(cfg, customHttp.NewClient(customHttp.DefaultHTTPConfig()), customHttp.NewRateLimiter(true))
+	executor.Efetch.BaseURL = srv.URL
+
+	result := &types.ESummaryResult{}
+	for _, uid := range []string{"3", "11", "12"} {
+		result.DocumentSummarySet.DocumentSummary = append(result.DocumentSummarySet.DocumentSummary, &types.DocumentSummary{Uid: uid})
+	}
+
+	if err := executor.Enrich(context.Background(), nil, 20, 3, result, types.NewQuery("BRCA1[gene]")); err != nil {
+		t.Fatalf("enrich by uid: %v", err)
+	}
+
+	if want := []string{"3,11", "12"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("got efetch id batches %v, want %v", requests, want)
+	}
+	for _, doc := range result.DocumentSummarySet.DocumentSummary {
+		if doc.VCV == nil || doc.VCV.VariationID != doc.Uid {
+			t.Errorf("uid %s has no vcv record attached", doc.Uid)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// ResultSink 接收每个 esummary 批次的结果, 使结果在查询执行期间即可写入文件
+// 返回错误 (如写入端已停止接收) 时该批次按失败处理
+type ResultSink func(ctx context.Context, batch *types.ESummaryResult) error
+
+// PipelineOption 定义 Pipeline 的可选配置
+type PipelineOption func(p *Pipeline)
+
+// WithResultSink 设置批次结果的接收者
+// 设置接收者后查询结果中只保留每条记录的 UID, 用于统计、核对及拆分查询去重, 内存占用与查询的记录数无关
+// 需要缓存时由批次缓存在每个批次完成后保存文档摘要
+func WithResultSink(sink ResultSink) PipelineOption {
+	return func(p *Pipeline) {
+		p.Summary.sink = sink
+	}
+}
+
+// emit 将批次结果发送给接收者, 未设置接收者时直接返回
+func (e *EsummaryExecutor) emit(ctx context.Context, batch *types.ESummaryResult) error {
+	if e.sink == nil || batch == nil || len(batch.DocumentSummarySet.DocumentSummary) == 0 {
+		return nil
+	}
+	return e.sink(ctx, batch)
+}
+
+// retained 返回查询结果中保留的批次结果
+// 批次已发送给接收者时创建只包含 UID 的副本, 不修改接收者持有的批次; 未设置接收者时保留完整结果
+func (e *EsummaryExecutor) retained(batch *types.ESummaryResult) *types.ESummaryResult {
+	if e.sink == nil || batch == nil {
+		return batch
+	}
+
+	docs := make([]*types.DocumentSummary, 0, len(batch.DocumentSummarySet.DocumentSummary))
+	for _, doc := range batch.DocumentSummarySet.DocumentSummary {
+		docs = append(docs, &types.DocumentSummary{Uid: doc.Uid})
+	}
+
+	return &types.ESummaryResult{
+		DocumentSummarySet: types.DocumentSummarySet{DocumentSummary: docs},
+	}
+}
@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// checkSplit 检查 esearch 结果数量是否超过单个查询的上限
+// 超过上限且查询包含多个检索词时返回拆分后的子查询, 否则将 esearch 结果转发给 esummary 并返回 nil
+func (p *Pipeline) checkSplit(ctx context.Context, query *types.Query, searchChan <-chan *types.ESearchResult, summaryChan chan<- *types.ESearchResult) []*types.Query {
+	select {
+	case searchResult := <-searchChan:
+		limit := p.Config.Runtime.MaxQueryRecords
+		if searchResult != nil && limit > 0 && searchResult.Count > limit {
+			if subQueries := query.Split(); subQueries != nil {
+				logcdl.Warn("query '%v' matched %d records (limit %d), splitting into sub-queries %v and %v",
+					query, searchResult.Count, limit, subQueries[0], subQueries[1])
+				return subQueries
+			}
+			logcdl.Warn("query '%v' matched %d records (limit %d) but contains a single term and cannot be split",
+				query, searchResult.Count, limit)
+		}
+		summaryChan <- searchResult
+	case <-ctx.Done():
+		// esummary 会在等待 esearch 结果时处理超时
+	}
+
+	return nil
+}
+
+// executeSplit 依次执行子查询并将结果合并到原查询的统计信息中
+// 子查询可能再次拆分; 多个检索词可能命中同一变异, 合并时按 Uid 去重
+func (p *Pipeline) executeSplit(ctx context.Context, query *types.Query, subQueries []*types.Query, queryStats *types.QueryResult) (*types.QueryResult, error) {
+	merged := &types.ESummaryResult{}
+	seen := make(map[string]struct{})
+
+	var batchKeys []string // 子查询的文档摘要保存在各自的批次缓存中
+	var totalBatches, failed int
+	var lastErr error
+	for _, subQuery := range subQueries {
+		subStats, err := p.ExecuteQuery(ctx, subQuery)
+		if err != nil {
+			failed++
+			lastErr = err
+			logcdl.Error("sub-query '%v' of query '%v' failed: %v", subQuery, query, err)
+			continue
+		}
+
+		if len(subStats.FailedBatches) > 0 {
+			logcdl.Warn("sub-query '%v' of query '%v' is incomplete: %d failed batches",
+				subQuery, query, len(subStats.FailedBatches))
+		}
+
+		totalBatches += subStats.TotalBatches
+		batchKeys = append(batchKeys, subStats.BatchKeys...)
+		if subStats.Result == nil {
+			continue
+		}
+
+		for _, doc := range subStats.Result.DocumentSummarySet.DocumentSummary {
+			if _, ok := seen[doc.Uid]; ok {
+				continue
+			}
+			seen[doc.Uid] = struct{}{}
+			merged.DocumentSummarySet.DocumentSummary = append(merged.DocumentSummarySet.DocumentSummary, doc)
+		}
+	}
+
+	hasFilters := p.Config.EntrezParams.Filters != ""
+	if failed == len(subQueries) {
+		err := fmt.Errorf("all %d sub-queries of query '%v' failed: %w", len(subQueries), query, lastErr)
+		queryStats.SetStatusOnError(err, hasFilters)
+		return queryStats, err
+	}
+
+	// 总记录数沿用原查询 esearch 返回的数量, 子查询失败时结果为部分成功
+	// 子查询失败批次的 retstart 基于子查询的 WebEnv, 无法在原查询上重试, 因此不记录失败批次
+	queryStats.Result = merged
+	queryStats.ProcessedCount = len(merged.DocumentSummarySet.DocumentSummary)
+	if queryStats.ProcessedCount > queryStats.TotalRecords {
+		queryStats.TotalRecords = queryStats.ProcessedCount // 子查询执行期间有新增记录
+	}
+	queryStats.TotalBatches = totalBatches
+	queryStats.SplitQueries = len(subQueries)
+	queryStats.BatchKeys = batchKeys
+	queryStats.Error = nil
+	queryStats.UpdateBasicStatus(hasFilters)
+
+	logcdl.Info("merged %d sub-queries of query '%v': %d/%d records",
+		len(subQueries), query, queryStats.ProcessedCount, queryStats.TotalRecords)
+
+	return queryStats, nil
+}
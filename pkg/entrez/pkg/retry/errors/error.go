@@ -12,6 +12,7 @@ const (
 	ErrFailedOpenFile   = Error("open file error")
 	ErrInvalidParameter = Error("invalid parameter")
 	ErrRetryFailed      = Error("retry failed")
+	ErrResponseTooLarge = Error("response too large")
 )
 
 // Error 定义错误类型
@@ -0,0 +1,133 @@
+package errors
+
+import "strings"
+
+// EutilsErrorKind 定义 E-utilities 响应体中错误信息的类别
+type EutilsErrorKind int
+
+const (
+	EutilsUnknown      EutilsErrorKind = iota // 未识别的错误, 按临时错误处理
+	EutilsBackend                             // 后端超时、繁忙或限流, 可以重试
+	EutilsBadTerm                             // 检索式错误, 重试结果相同
+	EutilsUnknownField                        // 检索式中使用了数据库不支持的字段, 重试结果相同
+	EutilsBadRequest                          // 数据库、UID 等请求参数错误, 重试结果相同
+	EutilsEmptyResult                         // 响应中没有错误信息也没有记录 (如空的 DocumentSummarySet), 可以重试
+)
+
+func (k EutilsErrorKind) String() string {
+	switch k {
+	case EutilsBackend:
+		return "backend"
+	case EutilsBadTerm:
+		return "bad term"
+	case EutilsUnknownField:
+		return "unknown field"
+	case EutilsBadRequest:
+		return "bad request"
+	case EutilsEmptyResult:
+		return "empty result"
+	default:
+		return "unknown"
+	}
+}
+
+// eutilsErrorPatterns E-utilities 错误信息 (小写) 与类别的对应关系, 按顺序匹配
+var eutilsErrorPatterns = []struct {
+	pattern string
+	kind    EutilsErrorKind
+}{
+	{"backend", EutilsBackend},
+	{"timeout", EutilsBackend},
+	{"timed out", EutilsBackend},
+	{"temporarily unavailable", EutilsBackend},
+	{"service unavailable", EutilsBackend},
+	{"internal server error", EutilsBackend},
+	{"try again", EutilsBackend},
+	{"rate limit", EutilsBackend},
+	{"unknown field", EutilsUnknownField},
+	{"field not found", EutilsUnknownField},
+	{"fieldnotfound", EutilsUnknownField},
+	{"invalid field", EutilsUnknownField},
+	{"empty term", EutilsBadTerm},
+	{"phrase not found", EutilsBadTerm},
+	{"syntax error", EutilsBadTerm},
+	{"illegal character", EutilsBadTerm},
+	{"invalid term", EutilsBadTerm},
+	{"invalid query", EutilsBadTerm},
+	{"database is not supported", EutilsBadRequest},
+	{"invalid db", EutilsBadRequest},
+	{"invalid uid", EutilsBadRequest},
+	{"invalid id", EutilsBadRequest},
+}
+
+// EutilsError 定义 E-utilities 以 HTTP 200 返回、包含在响应体中的错误
+// 如: <ERROR>Search Backend failed: ...</ERROR>、{"error": "API rate limit exceeded"}
+// Error 原样返回 NCBI 给出的错误信息, 记录到失败批次时不做修改
+type EutilsError struct {
+	op   string // 返回错误的操作, 如: esearch、esummary
+	msg  string
+	kind EutilsErrorKind
+}
+
+// NewEutilsError 根据错误信息创建 E-utilities 错误并判断类别
+func NewEutilsError(op, msg string) *EutilsError {
+	msg = strings.TrimSpace(msg)
+	return &EutilsError{
+		op:   op,
+		msg:  msg,
+		kind: classifyEutilsMessage(msg),
+	}
+}
+
+// NewEutilsEmptyResultError 创建响应中没有错误信息也没有记录时的错误
+func NewEutilsEmptyResultError(op, msg string) *EutilsError {
+	return &EutilsError{
+		op:   op,
+		msg:  msg,
+		kind: EutilsEmptyResult,
+	}
+}
+
+// ClassifyEutilsError 转换响应体中的错误信息
+// 历史会话过期返回 HistoryExpiredError, 由 esummary 重新获取 WebEnv 后重试; 其他返回 EutilsError
+func ClassifyEutilsError(op, msg string) error {
+	if IsHistoryExpired(msg) {
+		return NewHistoryExpiredError(strings.TrimSpace(msg))
+	}
+	return NewEutilsError(op, msg)
+}
+
+func (e *EutilsError) Error() string {
+	return e.msg
+}
+
+// Op 返回错误来源的操作
+func (e *EutilsError) Op() string {
+	return e.op
+}
+
+// Kind 返回错误类别
+func (e *EutilsError) Kind() EutilsErrorKind {
+	return e.kind
+}
+
+// ShouldRetry 后端错误、空结果及未识别的错误可以重试, 检索式及请求参数错误重试结果相同
+func (e *EutilsError) ShouldRetry() bool {
+	switch e.kind {
+	case EutilsBadTerm, EutilsUnknownField, EutilsBadRequest:
+		return false
+	default:
+		return true
+	}
+}
+
+// classifyEutilsMessage 根据错误信息判断类别
+func classifyEutilsMessage(msg string) EutilsErrorKind {
+	msg = strings.ToLower(msg)
+	for _, p := range eutilsErrorPatterns {
+		if strings.Contains(msg, p.pattern) {
+			return p.kind
+		}
+	}
+	return EutilsUnknown
+}
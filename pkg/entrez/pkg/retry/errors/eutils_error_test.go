@@ -0,0 +1,66 @@
+package errors
+
+import "testing"
+
+func TestClassifyEutilsError(t *testing.T) {
+	tests := []struct {
+		msg     string
+		kind    EutilsErrorKind
+		expired bool
+		retry   bool
+	}{
+		{msg: "Search Backend failed: Couldn't resolve #exLinkSrv2, the address table is empty.", kind: EutilsBackend, retry: true},
+		{msg: "Search Backend failed: read timeout", kind: EutilsBackend, retry: true},
+		{msg: "API rate limit exceeded", kind: EutilsBackend, retry: true},
+		{msg: "Service unavailable, please try again later", kind: EutilsBackend, retry: true},
+		{msg: "Empty term and query_key - nothing todo", kind: EutilsBadTerm},
+		{msg: "Syntax error in query", kind: EutilsBadTerm},
+		{msg: "field not found: genesymbol", kind: EutilsUnknownField},
+		{msg: "Unknown field [genesymbol]", kind: EutilsUnknownField},
+		{msg: "Invalid db name specified: clinvarx", kind: EutilsBadRequest},
+		{msg: "Invalid uid 99999999999 at position=0", kind: EutilsBadRequest},
+		{msg: "Something unexpected happened", kind: EutilsUnknown, retry: true},
+		{msg: "Unable to obtain query #1", expired: true},
+		{msg: "  Cannot retrieve query from history  ", expired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := ClassifyEutilsError("esearch", tt.msg)
+
+			if tt.expired {
+				if _, ok := err.(*HistoryExpiredError); !ok {
+					t.Fatalf("got %T, want *HistoryExpiredError", err)
+				}
+				if err.(RetryableError).ShouldRetry() {
+					t.Error("history expired error should not be retried before the session is renewed")
+				}
+				return
+			}
+
+			eutilsErr, ok := err.(*EutilsError)
+			if !ok {
+				t.Fatalf("got %T, want *EutilsError", err)
+			}
+			if eutilsErr.Kind() != tt.kind {
+				t.Errorf("kind = %v, want %v", eutilsErr.Kind(), tt.kind)
+			}
+			if eutilsErr.ShouldRetry() != tt.retry {
+				t.Errorf("ShouldRetry() = %v, want %v", eutilsErr.ShouldRetry(), tt.retry)
+			}
+			if eutilsErr.Error() != tt.msg {
+				t.Errorf("message = %q, want NCBI message %q unchanged", eutilsErr.Error(), tt.msg)
+			}
+			if eutilsErr.Op() != "esearch" {
+				t.Errorf("op = %q, want esearch", eutilsErr.Op())
+			}
+		})
+	}
+}
+
+func TestEutilsEmptyResultRetries(t *testing.T) {
+	err := NewEutilsEmptyResultError("esummary", "esummary returned an empty DocumentSummarySet")
+	if err.Kind() != EutilsEmptyResult || !err.ShouldRetry() {
+		t.Errorf("empty result: kind = %v, ShouldRetry() = %v, want empty result and retry", err.Kind(), err.ShouldRetry())
+	}
+}
@@ -0,0 +1,56 @@
+package errors
+
+import "strings"
+
+// historyExpiredMessages NCBI 历史会话 (WebEnv/query_key) 过期或失效时返回的错误信息
+var historyExpiredMessages = []string{
+	"unable to obtain query",
+	"cannot retrieve query",
+	"cannot retrieve history",
+	"invalid webenv",
+	"webenv is expired",
+}
+
+// HistoryExpiredError 定义 NCBI 历史会话过期错误
+// 使用过期的 WebEnv 重试没有意义, 只有在重新执行 esearch 替换 WebEnv 后才可以重试
+type HistoryExpiredError struct {
+	msg     string
+	renewed bool // WebEnv 是否已被替换
+}
+
+// NewHistoryExpiredError 创建历史会话过期错误
+func NewHistoryExpiredError(msg string) *HistoryExpiredError {
+	return &HistoryExpiredError{
+		msg: msg,
+	}
+}
+
+// Renewed 返回 WebEnv 已被替换的错误副本, 该错误可以重试
+func (e *HistoryExpiredError) Renewed() *HistoryExpiredError {
+	return &HistoryExpiredError{
+		msg:     e.msg,
+		renewed: true,
+	}
+}
+
+func (e *HistoryExpiredError) Error() string {
+	if e.renewed {
+		return "history session expired and was renewed: " + e.msg
+	}
+	return "history session expired: " + e.msg
+}
+
+func (e *HistoryExpiredError) ShouldRetry() bool {
+	return e.renewed
+}
+
+// IsHistoryExpired 判断 E-utilities 返回的错误信息是否表示历史会话过期
+func IsHistoryExpired(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, expired := range historyExpiredMessages {
+		if strings.Contains(msg, expired) {
+			return true
+		}
+	}
+	return false
+}
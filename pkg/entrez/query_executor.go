@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/cache"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/config"
@@ -16,11 +18,20 @@ import (
 )
 
 type QueryExecutor struct {
-	stats       *types.Stats // 统计信息
+	stats       *types.Stats  // 统计信息
+	dedup       *deduplicator // 结果去重
 	Config      *config.Config
 	httpClient  *customHttp.Client
 	rateLimiter *customHttp.RateLimiter
 	cache       cache.Cache
+	cacheStats  cacheStats // 本次运行的缓存命中情况
+}
+
+// cacheStats 统计单次运行中查询缓存的命中情况
+type cacheStats struct {
+	hits    atomic.Int64 // 命中完整缓存
+	resumed atomic.Int64 // 缓存不完整, 从批次缓存继续执行
+	misses  atomic.Int64 // 未命中缓存
 }
 
 func NewQueryExecutor(config *config.Config) *QueryExecutor {
@@ -29,17 +40,22 @@ func NewQueryExecutor(config *config.Config) *QueryExecutor {
 	// 缓存启用时初始化缓存
 	var cacheStore cache.Cache
 	if config.Cache.Enabled {
-		var err error
-		cacheStore, err = cache.NewFileCache(config.Cache.Dir, config.Cache.TTL)
+		store, err := cache.New(config.Cache)
 		if err != nil {
 			logcdl.Warn("failed to create cache: %v", err)
 		} else {
-			logcdl.Info("cache enabled at: '%s'", config.Cache.Dir)
+			// 启动时清理过期及旧版本格式的缓存
+			if err := store.CleanExpired(); err != nil {
+				logcdl.Warn("failed to clean expired cache: %v", err)
+			}
+			cacheStore = store
+			logcdl.Info("cache enabled at: '%s' (backend: %s)", config.Cache.Dir, config.Cache.Backend)
 		}
 	}
 
 	return &QueryExecutor{
 		stats:       stats,
+		dedup:       newDeduplicator(),
 		Config:      config,
 		httpClient:  customHttp.GetHTTPClient(),
 		rateLimiter: customHttp.NewRateLimiter(config.EntrezParams.ApiKey != ""),
@@ -47,36 +63,97 @@ func NewQueryExecutor(config *config.Config) *QueryExecutor {
 	}
 }
 
+// withConfig 创建使用新配置的查询执行器
+// 新执行器与当前执行器共享 HTTP 客户端、限流器及缓存, 统计信息单独计算
+func (q *QueryExecutor) withConfig(config *config.Config) *QueryExecutor {
+	return &QueryExecutor{
+		stats:       types.NewStats(),
+		dedup:       newDeduplicator(),
+		Config:      config,
+		httpClient:  q.httpClient,
+		rateLimiter: q.rateLimiter,
+		cache:       q.cache,
+	}
+}
+
+// close 关闭缓存, 与其共享缓存的执行器不能再使用
+func (q *QueryExecutor) close() error {
+	if q.cache == nil {
+		return nil
+	}
+	return q.cache.Close()
+}
+
 // executeQueries 执行查询并返回结果通道
+// 查询在后台执行, 每个 esummary 批次完成后即发送到结果通道, 写入端可与查询同时进行
+// 所有查询完成后打印统计信息并关闭结果通道
 func (q *QueryExecutor) executeQueries(ctx context.Context, queries []*types.Query) (<-chan *types.QueryResult, error) {
 	logcdl.Info("created query executor with request rate of %.2f requests/second", q.rateLimiter.GetCurrentRate())
 
-	bufferSize := utils.GetWorkerCount(q.Config.Runtime.BufferSize, len(queries))
-
-	results := make(chan *types.QueryResult, bufferSize)
-
 	// 获取查询并发数，不超过查询数，避免多开协程造成资源浪费
 	maxWorkers := q.Config.Runtime.GetQueryWorkers()
 	queryWorkers := utils.GetWorkerCount(maxWorkers, len(queries))
 
+	// 结果通道只缓冲正在执行的批次, 写入端较慢时查询等待写入, 内存占用与总记录数无关
+	bufferSize := utils.GetWorkerCount(queryWorkers*q.Config.Runtime.GetEsummaryWorkers(), q.Config.Runtime.BufferSize)
+
+	results := make(chan *types.QueryResult, bufferSize)
+	deduplicated := make(chan *types.QueryResult, bufferSize)
+
 	logcdl.Tip("starting to submit %d queries with %d concurrent workers",
 		len(queries), queryWorkers)
 
-	// 启动查询执行
-	q.processQueries(ctx, queries, results, queryWorkers)
-
 	// 设置总查询数
 	q.stats.SetTotalQueries(len(queries))
 
-	// 处理统计信息
-	q.stats.PrintSummary()
+	// 启动查询执行
+	go q.processQueries(ctx, queries, results, queryWorkers)
+
+	// 按 VariationID 对所有查询的结果去重
+	startedAt := time.Now()
+	go func() {
+		defer close(deduplicated)
+
+		q.dedup.run(ctx, results, deduplicated)
+		q.stats.SetDuplicates(q.dedup.duplicates, len(q.dedup.order))
+
+		// 处理统计信息
+		q.stats.PrintSummary()
+		q.recordCacheStats(startedAt, len(queries))
+	}()
+
+	return deduplicated, nil
+}
+
+// recordCacheStats 在缓存目录中记录本次运行的缓存命中情况, 供 cache stats 命令查看
+func (q *QueryExecutor) recordCacheStats(startedAt time.Time, queries int) {
+	if q.cache == nil {
+		return
+	}
+
+	run := &cache.RunStats{
+		StartedAt: startedAt,
+		Queries:   queries,
+		Hits:      int(q.cacheStats.hits.Load()),
+		Resumed:   int(q.cacheStats.resumed.Load()),
+		Misses:    int(q.cacheStats.misses.Load()),
+	}
+	if err := cache.RecordRun(q.Config.Cache.Dir, run); err != nil {
+		logcdl.Debug("failed to record cache stats: %v", err)
+	}
+}
 
-	// 如果所有查询都失败了，返回 ErrEmptyResult
+// checkStats 检查查询统计信息, 所有查询都失败时返回 ErrEmptyResult, 只能在结果通道关闭后调用
+func (q *QueryExecutor) checkStats() error {
 	if q.stats.AllQueriesFailed() {
-		return nil, customerrors.NewEmptyResultError(fmt.Sprintf("all %d queries failed, please check the logs and try again later", len(queries)))
+		return customerrors.NewEmptyResultError(fmt.Sprintf("all %d queries failed, please check the logs and try again later", q.stats.TotalQueries))
 	}
+	return nil
+}
 
-	return results, nil
+// sharedVariants 返回出现在多个查询中的变异及其所有来源, 只能在结果通道关闭后调用
+func (q *QueryExecutor) sharedVariants() []*types.SharedVariant {
+	return q.dedup.sharedVariants()
 }
 
 // processQueries 处理所有查询
@@ -120,19 +197,44 @@ func (q *QueryExecutor) executeQuery(ctx context.Context, query *types.Query, re
 	}()
 
 	// 先尝试从缓存获取
-	if result := q.tryGetFromCache(ctx, query); result != nil {
-		// 处理缓存命中
-		results <- result
-		q.stats.AddProcessedRecords(result.ProcessedCount)
-		q.stats.AddTotalRecords(result.TotalRecords)
-		if result.Status == types.QueryStatusSuccess {
-			q.stats.AddCompletedQuery()
+	if result := q.tryGetFromCache(query); result != nil {
+		// 处理缓存命中, 基因名映射以本次输入为准; 缓存中的结果可能被其他查询共用, 修改前先复制
+		result = result.WithResolvedSymbols(query.ResolvedSymbols)
+		err := q.replayCached(ctx, result, results)
+		if err == nil {
+			q.stats.AddProcessedRecords(result.ProcessedCount)
+			q.stats.AddTotalRecords(result.TotalRecords)
+			if result.Status == types.QueryStatusSuccess {
+				q.stats.AddCompletedQuery()
+			}
+			return
+		}
+
+		// 已发送的记录在重新执行时再次发送, 由去重移除
+		q.cacheStats.hits.Add(-1)
+		q.cacheStats.misses.Add(1)
+		logcdl.Warn("failed to read cached batches for query '%v', querying again: %v", queryID, err)
+	}
+
+	// 每个 esummary 批次完成后即发送给写入端
+	sink := func(ctx context.Context, batch *types.ESummaryResult) error {
+		select {
+		case results <- &types.QueryResult{QueryID: queryID, Query: query.Content, Result: batch, Regions: query.Regions, ResolvedSymbols: query.ResolvedSymbols}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		return
 	}
 
 	// 缓存未命中，执行单个查询的完整流程
-	queryStats, err := pipeline.NewPipeline(q.Config, q.httpClient, q.rateLimiter).ExecuteQuery(ctx, query)
+	// 查询结果只保留 UID, 启用缓存时文档摘要在每个批次完成后写入批次缓存
+	opts := []pipeline.PipelineOption{pipeline.WithResultSink(sink)}
+	// 已缓存的批次不再请求, 查询中断或超时后再次执行时只获取缺失的批次
+	if batches, ok := q.cache.(cache.BatchCache); ok {
+		opts = append(opts, pipeline.WithBatchCache(batches))
+	}
+	p := pipeline.NewPipeline(q.Config, q.httpClient, q.rateLimiter, opts...)
+	queryStats, err := p.ExecuteQuery(ctx, query)
 	// 如果单个查询失败，则添加到失败查询列表且不生成缓存数据,下次查询时会发起新的 NewPipeline
 	if err != nil {
 		q.stats.FailedQueries.Store(queryID, err)
@@ -145,106 +247,91 @@ func (q *QueryExecutor) executeQuery(ctx context.Context, query *types.Query, re
 			q.stats.FailedQueries.Store(queryID, fmt.Errorf("query result is nil"))
 			return
 		}
-		if err := q.cache.Set(queryID, queryStats); err != nil {
+		fingerprint := cache.NewFingerprint(query, q.Config)
+		key := fingerprint.Key(query)
+		queryStats.SetFingerprint(fingerprint.String())
+		if err := q.cache.Set(key, queryStats); err != nil {
 			logcdl.Warn("failed to cache result for query '%v': %v", queryID, err)
 		}
 	}
 
-	// 发送结果
-	select {
-	case results <- &types.QueryResult{Query: query.Content, Result: queryStats.Result}:
-		if queryStats.Status == types.QueryStatusSuccess {
-			q.stats.AddCompletedQuery()
+	// 结果已按批次发送, 只更新统计信息
+	if queryStats.Status == types.QueryStatusSuccess {
+		q.stats.AddCompletedQuery()
+	}
+	q.stats.AddProcessedRecords(queryStats.ProcessedCount)
+	q.stats.AddTotalRecords(queryStats.TotalRecords)
+	// 添加部分失败批次的打印信息
+	if len(queryStats.FailedBatches) > 0 {
+		failedBatches := types.Batch{
+			Batches:    queryStats.TotalBatches,
+			BatchInfos: queryStats.FailedBatches,
 		}
-		q.stats.AddProcessedRecords(queryStats.ProcessedCount)
-		q.stats.AddTotalRecords(queryStats.TotalRecords)
-		// 添加部分失败批次的打印信息
-		if len(queryStats.FailedBatches) > 0 {
-			failedBatches := types.Batch{
-				Batches:    queryStats.TotalBatches,
-				BatchInfos: queryStats.FailedBatches,
+		q.stats.AddPartialFailures(queryID, failedBatches)
+	}
+	if len(queryStats.MissingUIDs) > 0 {
+		q.stats.AddMissingUIDs(queryID, queryStats.MissingUIDs)
+	}
+}
+
+// replayCached 按批次读取缓存命中的查询的文档摘要并发送给写入端
+// 批次缓存缺失、过期或记录数不足时返回错误, 由调用方重新执行查询
+func (q *QueryExecutor) replayCached(ctx context.Context, result *types.QueryResult, results chan<- *types.QueryResult) error {
+	batches, ok := q.cache.(cache.BatchCache)
+	if !ok {
+		return fmt.Errorf("cache backend does not store batches")
+	}
+
+	var sent int
+	for _, key := range result.BatchKeys {
+		err := batches.EachBatch(key, func(batch *types.ESummaryResult) error {
+			select {
+			case results <- &types.QueryResult{QueryID: result.QueryID, Query: result.Query, Result: batch, Regions: result.Regions, ResolvedSymbols: result.ResolvedSymbols}:
+				sent += len(batch.DocumentSummarySet.DocumentSummary)
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
 			}
-			q.stats.AddPartialFailures(queryID, failedBatches)
+		})
+		if err != nil {
+			return err
 		}
-	case <-ctx.Done():
-		logcdl.Error("context cancelled while sending results for query '%v'", query)
-		q.stats.FailedQueries.Store(queryID, ctx.Err())
 	}
+
+	if sent < result.ProcessedCount {
+		return fmt.Errorf("cached batches contain %d of %d records", sent, result.ProcessedCount)
+	}
+
+	return nil
 }
 
 // tryGetFromCache 尝试从缓存获取结果
-func (q *QueryExecutor) tryGetFromCache(ctx context.Context, query *types.Query) *types.QueryResult {
+func (q *QueryExecutor) tryGetFromCache(query *types.Query) *types.QueryResult {
 	// 如果缓存未启用，则直接返回 nil
 	if q.cache == nil {
 		return nil
 	}
 
+	// 缓存键由查询内容及过滤条件、数据库、日期等请求参数共同决定, 参数变化时不会命中之前的缓存
 	queryID := query.GetQueryID()
-	queryResult, err := q.cache.Get(queryID)
+	queryResult, err := q.cache.Get(cache.NewFingerprint(query, q.Config).Key(query))
 	if err != nil {
+		q.cacheStats.misses.Add(1)
 		logcdl.Debug("cache get failed for query '%v': %v", queryID, err)
 		return nil
 	}
 
-	// 检查过滤条件是否发生变化, 如果发生变化，则返回 nil，更新缓存
-	currentHasFilters := q.Config.EntrezParams.Filters != ""
-	if queryResult.LastQueryHasFilters != currentHasFilters {
-		logcdl.Info("filter conditions changed for query '%v', ignoring cache", queryID)
-		return nil
-	}
-
 	// 如果缓存结果完整，直接返回
 	if queryResult.IsComplete() {
+		q.cacheStats.hits.Add(1)
 		logcdl.Info("using complete cached result for query '%v'", queryID)
 		return queryResult
 	}
 
-	// 尝试重试失败的批次, 如果成功，则更新缓存
-	// 如果失败，则返回原有缓存结果
-	updatedResult, err := q.retryFailedBatches(ctx, query, queryResult)
-	if err != nil {
-		logcdl.Warn("failed to retry failed batches for query '%v': %v", queryID, err)
-		return queryResult
-	}
-
-	if updatedResult != nil && updatedResult.IsComplete() {
-		logcdl.Success("successfully completed all batches for query '%v'", queryID)
-		return updatedResult
-	}
-
-	return queryResult
-}
-
-// retryFailedBatches 重试失败的批次并更新缓存
-func (q *QueryExecutor) retryFailedBatches(ctx context.Context, query *types.Query, cachedResult *types.QueryResult) (*types.QueryResult, error) {
-	queryID := query.GetQueryID()
-	logcdl.Info("retrying failed batches for query '%v'", queryID)
-
-	// 创建新的 Pipeline 实例
-	p := pipeline.NewPipeline(q.Config, q.httpClient, q.rateLimiter)
-
-	// 重试失败的批次
-	updatedResult := p.RetryFailedBatches(ctx, query, cachedResult)
-
-	// 检查重试结果
-	if updatedResult == nil {
-		return nil, fmt.Errorf("retry failed: nil result")
-	}
-
-	// 如果重试失败，返回错误但不更新缓存
-	if updatedResult.Error != nil {
-		return nil, updatedResult.Error
-	}
-
-	// 更新缓存
-	if q.cache != nil {
-		// 只有在状态为成功或部分成功时才更新缓存
-		if updatedResult.Status != types.QueryStatusFailed {
-			if err := q.cache.Set(queryID, updatedResult); err != nil {
-				logcdl.Warn("failed to update cache for query '%v': %v", queryID, err)
-			}
-		}
-	}
+	q.cacheStats.resumed.Add(1)
 
-	return updatedResult, nil
+	// 结果不完整时重新执行查询, 已完成的批次从批次缓存中读取, 只请求缺失的批次
+	// 重新执行时会核对 esearch 返回的记录数, 记录数变化时该查询的全部批次失效
+	logcdl.Info("incomplete cached result for query '%v', resuming from cached batches", queryID)
+	return nil
 }
@@ -29,6 +29,20 @@ func NewEntrezService(config *config.Config) *EntrezService {
 	return service
 }
 
+// WithConfig 返回使用新配置的 EntrezService, 用于一次运行中执行多个任务
+// 新实例与当前实例共享 HTTP 客户端、限流器及缓存，保证多个任务的总请求速率不超过限制
+func (s *EntrezService) WithConfig(config *config.Config) *EntrezService {
+	return &EntrezService{
+		Config:   config,
+		executor: s.executor.withConfig(config),
+	}
+}
+
+// Close 关闭缓存等共享资源, 所有任务完成后调用
+func (s *EntrezService) Close() error {
+	return s.executor.close()
+}
+
 // ExecuteQueries 执行查询并返回结果通道
 func (s *EntrezService) ExecuteQueries(ctx context.Context, queries []*types.Query) (<-chan *types.QueryResult, error) {
 	return s.executor.executeQueries(ctx, queries)
@@ -41,7 +55,8 @@ func (s *EntrezService) ProcessResults(ctx context.Context, results <-chan *type
 	}
 
 	// 创建一个新的 context，避免外部 context 取消影响写入操作
-	writeCtx, cancel := context.WithTimeout(ctx, s.Config.Runtime.WriteTimeout)
+	// 写入与查询同时进行, 超时时间包含查询时间; 查询超时后结果通道随即关闭, 已收到的结果仍会写入
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.Config.Runtime.QueryTimeout+s.Config.Runtime.WriteTimeout)
 	defer cancel()
 
 	// 只有当有结果需要处理时才初始化 writer
@@ -88,6 +103,18 @@ func (s *EntrezService) ProcessResults(ctx context.Context, results <-chan *type
 		return writeCtx.Err()
 	}
 
+	// 结果通道关闭后检查查询统计信息, 所有查询都失败时不保存结果
+	if err := s.executor.checkStats(); err != nil {
+		return err
+	}
+
+	// 将出现在多个查询中的变异的所有来源写回该变异所在的行
+	if originWriter, ok := resultWriter.(output.OriginWriter); ok {
+		if err := originWriter.UpdateOrigins(s.executor.sharedVariants()); err != nil {
+			return errors.Wrapf(customerrors.ErrSaveResult, "failed to update variant origins: %v", err)
+		}
+	}
+
 	// 保存结果
 	if err := resultWriter.Save(outputFile); err != nil {
 		return errors.Wrapf(customerrors.ErrSaveResult, "failed to save results: %v", err)
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EFetchOperation 实现了 efetch 操作, 获取 rettype=vcv 的完整 VCV 记录
+type EFetchOperation struct {
+	BaseOperation
+}
+
+// NewEFetchOperation 创建一个新的 EFetchOperation 实例
+func NewEFetchOperation(httpClient *http.Client, rateLimiter *http.RateLimiter) *EFetchOperation {
+	op := &EFetchOperation{
+		BaseOperation: *NewBaseOperation(BaseURLEFetch, httpClient, rateLimiter),
+	}
+
+	// VCV 记录只提供 XML 格式
+	op.Parameters.Set("rettype", "vcv")
+	op.Parameters.Set("retmode", string(response.ParserXML))
+	op.Parameters.Set("is_variationid", "")
+
+	return op
+}
+
+// Execute 使用 esearch 返回的 WebEnv 及 query_key 获取 [start, start+size) 范围内的 VCV 记录
+func (f *EFetchOperation) Execute(ctx context.Context, webEnv, queryKey string, start, size int, query *types.Query) (*types.EFetchResult, error) {
+	// 每次请求使用独立的参数, 避免并发批次之间相互覆盖分页参数
+	params := f.newParameters()
+	params.Set("WebEnv", webEnv)
+	params.Set("query_key", queryKey)
+	params.Set("retstart", fmt.Sprintf("%d", start))
+	params.Set("retmax", fmt.Sprintf("%d", size))
+
+	efetchURL, err := f.buildURL(params)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to build efetch url for query '%v'", query)
+	}
+
+	// 打印 URL 信息
+	urlString, _ := url.QueryUnescape(efetchURL.String())
+	logcdl.Debug("efetch url for query '%v': '%s'", query, urlString)
+
+	body, err := f.doRequest(ctx, "GET", efetchURL.String(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建解析器并解析响应
+	parser, err := response.NewEFetchResponseParser(response.ParserType(f.GetRetMode()))
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.ParseEFetch(body)
+}
+
+// ExecuteIDs 通过 VariationID 列表直接获取 VCV 记录, 不依赖 WebEnv 及 query_key
+func (f *EFetchOperation) ExecuteIDs(ctx context.Context, ids []string, query *types.Query) (*types.EFetchResult, error) {
+	params := f.newParameters()
+	params.Set("id", strings.Join(ids, ","))
+
+	logcdl.Debug("efetch %d ids for query '%v'", len(ids), query)
+
+	body, err := f.doRequest(ctx, "POST", f.BaseURL, params)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "efetch http request failed for query '%v'", query)
+	}
+
+	parser, err := response.NewEFetchResponseParser(response.ParserType(f.GetRetMode()))
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.ParseEFetch(body)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/pkg/errors"
+)
+
+// ELinkOperation 实现了 elink 操作, 获取 UID 在其他数据库中的关联记录
+type ELinkOperation struct {
+	BaseOperation
+}
+
+// NewELinkOperation 创建一个新的 ELinkOperation 实例
+func NewELinkOperation(httpClient *http.Client, rateLimiter *http.RateLimiter) *ELinkOperation {
+	op := &ELinkOperation{
+		BaseOperation: *NewBaseOperation(BaseURLELink, httpClient, rateLimiter),
+	}
+
+	op.Parameters.Set("cmd", "neighbor")
+	op.Parameters.Set("retmode", string(response.ParserXML))
+
+	return op
+}
+
+// SetDBFrom 设置源数据库, elink 的 db 参数为目标数据库, 在 Execute 时指定
+func (l *ELinkOperation) SetDBFrom(db string) *ELinkOperation {
+	l.Parameters.Set("dbfrom", db)
+	return l
+}
+
+// Execute 获取 ids 在目标数据库 dbTo 中的关联 UID
+// 每个 UID 使用单独的 id 参数, 使响应中每个 UID 对应一个 LinkSet; UID 较多时使用 POST 请求
+func (l *ELinkOperation) Execute(ctx context.Context, dbTo string, ids []string, query *types.Query) (*types.ELinkResult, error) {
+	// 每次请求使用独立的参数, 避免并发批次之间相互覆盖
+	params := l.newParameters()
+	params.Set("db", dbTo)
+	params["id"] = append([]string(nil), ids...)
+
+	logcdl.Debug("elink %s->%s for query '%v' with %d ids", params.Get("dbfrom"), dbTo, query, len(ids))
+
+	body, err := l.doRequest(ctx, "POST", l.BaseURL, params)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "elink http request failed for query '%v'", query)
+	}
+
+	// 创建解析器并解析响应
+	parser, err := response.NewELinkResponseParser(response.ParserType(l.GetRetMode()))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parser.ParseELink(body)
+	if err != nil {
+		return nil, err
+	}
+	result.DbTo = dbTo
+
+	return result, nil
+}
+
+// MedGenSummaryOperation 实现了 MedGen 数据库的 esummary 操作, 用于将 MedGen UID 转换为概念 ID (CUI)
+type MedGenSummaryOperation struct {
+	BaseOperation
+}
+
+// NewMedGenSummaryOperation 创建一个新的 MedGenSummaryOperation 实例
+func NewMedGenSummaryOperation(httpClient *http.Client, rateLimiter *http.RateLimiter) *MedGenSummaryOperation {
+	op := &MedGenSummaryOperation{
+		BaseOperation: *NewBaseOperation(BaseURLESummary, httpClient, rateLimiter),
+	}
+
+	op.Parameters.Set("db", types.LinkMedGen)
+	op.Parameters.Set("retmode", string(response.ParserXML))
+
+	return op
+}
+
+// Execute 获取 MedGen UID 对应的概念 ID
+func (m *MedGenSummaryOperation) Execute(ctx context.Context, uids []string, query *types.Query) (*types.MedGenSummaryResult, error) {
+	params := m.newParameters()
+	params.Set("id", strings.Join(uids, ","))
+
+	body, err := m.doRequest(ctx, "POST", m.BaseURL, params)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "medgen esummary http request failed for query '%v'", query)
+	}
+
+	parser, err := response.NewMedGenSummaryResponseParser(response.ParserType(m.GetRetMode()))
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.ParseMedGenSummary(body)
+}
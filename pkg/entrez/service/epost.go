@@ -2,9 +2,10 @@ package service
 
 import (
 	"context"
-	"net/url"
 	"strings"
 
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/http"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
 
@@ -17,23 +18,27 @@ type EPostOperation struct {
 }
 
 // NewEPostOperation 创建一个新的 EPostOperation 实例
-func NewEPostOperation() *EPostOperation {
-	return &EPostOperation{
-		BaseOperation: BaseOperation{
-			BaseURL:    BaseURLEPost,
-			Parameters: url.Values{},
-		},
+func NewEPostOperation(httpClient *http.Client, rateLimiter *http.RateLimiter) *EPostOperation {
+	op := &EPostOperation{
+		BaseOperation: *NewBaseOperation(BaseURLEPost, httpClient, rateLimiter),
 	}
+
+	// epost 不支持 retmode 参数, 始终返回 XML
+	op.Parameters.Set("retmode", string(response.ParserXML))
+
+	return op
 }
 
+// Execute 将 ids 上传至历史服务器, 返回可用于 esummary 的 WebEnv 及 query_key
 func (p *EPostOperation) Execute(ctx context.Context, ids []string, query *types.Query) (*types.EPostResult, error) {
-	p.Parameters.Set("id", strings.Join(ids, ","))
-	epostURL, err := p.BuildURL()
-	if err != nil {
-		return nil, errors.WithMessagef(err, "failed to build epost url for query '%v'", query)
-	}
+	// 每次请求使用独立的参数, 避免并发查询之间相互覆盖 id 参数
+	params := p.newParameters()
+	params.Set("id", strings.Join(ids, ","))
+
+	logcdl.Debug("epost %d ids for query '%v'", len(ids), query)
 
-	body, err := p.doRequest(ctx, "POST", epostURL.String(), p.Parameters)
+	// id 较多时 url 过长, 参数放在请求体中
+	body, err := p.doRequest(ctx, "POST", p.BaseURL, params)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "epost http request failed for query '%v'", query)
 	}
@@ -44,7 +49,7 @@ func (p *EPostOperation) Execute(ctx context.Context, ids []string, query *types
 		return nil, err
 	}
 
-	// 解析响应``
+	// 解析响应
 	result, err := parser.ParseEPost(body)
 	if err != nil {
 		return nil, err
@@ -6,6 +6,7 @@ import (
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response"
@@ -38,12 +39,73 @@ func (eo *ESearchOperation) SetQueryFilters(filters string) *ESearchOperation {
 	return eo
 }
 
+// SetDateType 设置日期范围使用的日期类型, 如: mdat、pdat、edat
+func (eo *ESearchOperation) SetDateType(dateType string) *ESearchOperation {
+	if dateType != "" {
+		eo.Parameters.Set("datetype", dateType)
+	} else {
+		eo.Parameters.Del("datetype")
+	}
+	return eo
+}
+
+// SetDateRange 设置日期范围, mindate 和 maxdate 必须成对出现
+func (eo *ESearchOperation) SetDateRange(minDate, maxDate string) *ESearchOperation {
+	if minDate != "" && maxDate != "" {
+		eo.Parameters.Set("mindate", minDate)
+		eo.Parameters.Set("maxdate", maxDate)
+	} else {
+		eo.Parameters.Del("mindate")
+		eo.Parameters.Del("maxdate")
+	}
+	return eo
+}
+
+// SetRelDate 设置只返回最近 days 天内的记录
+func (eo *ESearchOperation) SetRelDate(days int) *ESearchOperation {
+	if days > 0 {
+		eo.Parameters.Set("reldate", strconv.Itoa(days))
+	} else {
+		eo.Parameters.Del("reldate")
+	}
+	return eo
+}
+
+// SetSort 设置结果的排序方式
+func (eo *ESearchOperation) SetSort(sort string) *ESearchOperation {
+	if sort != "" {
+		eo.Parameters.Set("sort", sort)
+	} else {
+		eo.Parameters.Del("sort")
+	}
+	return eo
+}
+
 // Execute 执行 ESearch 操作
 func (eo *ESearchOperation) Execute(ctx context.Context, query *types.Query) (*types.ESearchResult, error) {
+	return eo.execute(ctx, eo.termParameters(query), query)
+}
+
+// ExecuteIDs 分页获取查询结果中 [retStart, retStart+retMax) 范围内的 UID
+// 只用于获取 UID 列表, 不上传至历史服务器
+func (eo *ESearchOperation) ExecuteIDs(ctx context.Context, query *types.Query, retStart, retMax int) (*types.ESearchResult, error) {
+	params := eo.termParameters(query)
+	params.Del("usehistory")
+	params.Set("retstart", strconv.Itoa(retStart))
+	params.Set("retmax", strconv.Itoa(retMax))
+
+	return eo.execute(ctx, params, query)
+}
+
+// termParameters 创建包含搜索词的单次请求参数
+// 每次请求使用独立的参数, 避免并发查询之间相互覆盖搜索词
+func (eo *ESearchOperation) termParameters(query *types.Query) url.Values {
+	params := eo.newParameters()
+
 	// 设置搜索词: 拼接 filters 和 query
 	// 重要逻辑：参考 clinvar advanced search 的搜索词格式
 	if eo.config.Filters == "" {
-		eo.Parameters.Set("term", "("+query.Content+")")
+		params.Set("term", "("+query.Content+")")
 	} else {
 		batchString := &strings.Builder{}
 		batchString.WriteString("(")
@@ -51,10 +113,15 @@ func (eo *ESearchOperation) Execute(ctx context.Context, query *types.Query) (*t
 		batchString.WriteString(" AND ")
 		batchString.WriteString(eo.config.Filters)
 		batchString.WriteString(")")
-		eo.Parameters.Set("term", batchString.String())
+		params.Set("term", batchString.String())
 	}
 
-	esearchURL, err := eo.BuildURL()
+	return params
+}
+
+// execute 使用单次请求的参数执行 ESearch 请求
+func (eo *ESearchOperation) execute(ctx context.Context, params url.Values, query *types.Query) (*types.ESearchResult, error) {
+	esearchURL, err := eo.buildURL(params)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "failed to build esearch url for query '%v'", query)
 	}
@@ -68,7 +135,7 @@ func (eo *ESearchOperation) Execute(ctx context.Context, query *types.Query) (*t
 		method = "POST"
 	}
 
-	body, err := eo.doRequest(ctx, method, esearchURL.String(), eo.Parameters)
+	body, err := eo.doRequest(ctx, method, esearchURL.String(), params)
 	if err != nil {
 		return nil, err
 	}
@@ -1,13 +1,15 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/http"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"io"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -24,57 +26,69 @@ func NewESummaryOperation(httpClient *http.Client, rateLimiter *http.RateLimiter
 	}
 }
 
-// Execute 执行 ESummary 操作
-func (s *ESummaryOperation) Execute(ctx context.Context, webEnv, queryKey string, query *types.Query) (*types.ESummaryResult, error) {
-	if s.BaseOperation.useStream {
+// Execute 执行 ESummary 操作, 获取 WebEnv 及 query_key 对应结果中 [start, start+retMax) 范围内的文档摘要
+// 流式处理只支持 XML 格式, 其他格式使用非流式处理
+func (s *ESummaryOperation) Execute(ctx context.Context, webEnv, queryKey string, start, retMax int, query *types.Query) (*types.ESummaryResult, error) {
+	params := s.requestParameters(webEnv, queryKey, start, retMax)
+
+	if s.BaseOperation.useStream && response.ParserType(s.GetRetMode()) == response.ParserXML {
 		logcdl.Debug("using stream for esummary")
-		return s.ExecuteStream(ctx, webEnv, queryKey, query)
+		return s.executeStream(ctx, params, query)
 	}
 	logcdl.Debug("using non-stream for esummary")
-	return s.ExecuteWithoutStream(ctx, webEnv, queryKey, query)
+	return s.executeWithoutStream(ctx, params, query)
 }
 
-// ExecuteStream 执行 ESummary 流式操作
-func (s *ESummaryOperation) ExecuteStream(ctx context.Context, webEnv, queryKey string, query *types.Query) (*types.ESummaryResult, error) {
-	var buffer bytes.Buffer
-
-	// 设置 ESummary 请求参数
-	s.Parameters.Set("WebEnv", webEnv)
-	s.Parameters.Set("query_key", queryKey)
+// requestParameters 创建单次 ESummary 请求的参数
+// 每个批次使用独立的参数, 并发批次之间不会相互覆盖 retstart 及 retmax
+func (s *ESummaryOperation) requestParameters(webEnv, queryKey string, start, retMax int) url.Values {
+	params := s.newParameters()
+	params.Set("WebEnv", webEnv)
+	params.Set("query_key", queryKey)
+	params.Set("retstart", strconv.Itoa(start))
+	params.Set("retmax", strconv.Itoa(retMax))
+	return params
+}
 
-	esummaryURL, err := s.BuildURL()
+// executeStream 执行 ESummary 流式操作, 将流式解析的文档摘要收集为一个批次的结果
+// 补充阶段及批次缓存均以批次为单位, 因此仍然返回整个批次; 流式解析只避免缓存原始响应体及完整的 XML 解析树
+func (s *ESummaryOperation) executeStream(ctx context.Context, params url.Values, query *types.Query) (*types.ESummaryResult, error) {
+	result := &types.ESummaryResult{}
+	err := s.stream(ctx, params, query, func(doc *types.DocumentSummary) error {
+		result.DocumentSummarySet.DocumentSummary = append(result.DocumentSummarySet.DocumentSummary, doc)
+		return nil
+	})
 	if err != nil {
-		return nil, errors.WithMessagef(err, "failed to build esummary url for query: '%v'", query)
+		return nil, err
 	}
 
-	// 打印 URL 信息
-	urlString, _ := url.QueryUnescape(esummaryURL.String())
-	logcdl.Debug("esummary stream url for query '%v': '%s'", query, urlString)
+	return result, nil
+}
 
-	// 使用流式处理并写入缓冲区
-	err = s.doStreamRequest(ctx, "GET", esummaryURL.String(), s.Parameters, func(chunk []byte) error {
-		_, err := buffer.Write(chunk)
-		return err
-	})
+// stream 发送 ESummary 请求并逐个 token 解析响应, 不缓存完整的响应体
+func (s *ESummaryOperation) stream(ctx context.Context, params url.Values, query *types.Query, handler func(doc *types.DocumentSummary) error) error {
+	parser, err := response.NewESummaryStreamParser(response.ParserType(s.GetRetMode()))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// 创建解析器并解析响应
-	parser, err := response.NewESummaryResponseParser(response.ParserType(s.GetRetMode()))
+	esummaryURL, err := s.buildURL(params)
 	if err != nil {
-		return nil, err
+		return errors.WithMessagef(err, "failed to build esummary url for query: '%v'", query)
 	}
-	return parser.ParseESummary(buffer.Bytes())
-}
 
-// ExecuteWithoutStream 执行 ESummary 非流式操作
-func (s *ESummaryOperation) ExecuteWithoutStream(ctx context.Context, webEnv, queryKey string, query *types.Query) (*types.ESummaryResult, error) {
-	// 设置 ESummary 请求参数
-	s.Parameters.Set("WebEnv", webEnv)
-	s.Parameters.Set("query_key", queryKey)
+	// 打印 URL 信息
+	urlString, _ := url.QueryUnescape(esummaryURL.String())
+	logcdl.Debug("esummary stream url for query '%v': '%s'", query, urlString)
 
-	esummaryURL, err := s.BuildURL()
+	return s.doStreamRequest(ctx, "GET", esummaryURL.String(), params, func(body io.Reader) error {
+		return parser.DecodeESummary(body, handler)
+	})
+}
+
+// executeWithoutStream 执行 ESummary 非流式操作
+func (s *ESummaryOperation) executeWithoutStream(ctx context.Context, params url.Values, query *types.Query) (*types.ESummaryResult, error) {
+	esummaryURL, err := s.buildURL(params)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "failed to build esummary url for query: '%v'", query)
 	}
@@ -83,7 +97,7 @@ func (s *ESummaryOperation) ExecuteWithoutStream(ctx context.Context, webEnv, qu
 	urlString, _ := url.QueryUnescape(esummaryURL.String())
 	logcdl.Debug("esummary url for query '%v': '%s'", query, urlString)
 
-	body, err := s.doRequest(ctx, "GET", esummaryURL.String(), s.Parameters)
+	body, err := s.doRequest(ctx, "GET", esummaryURL.String(), params)
 	if err != nil {
 		return nil, err
 	}
@@ -108,3 +122,24 @@ func (s *ESummaryOperation) ExecuteWithoutStream(ctx context.Context, webEnv, qu
 
 	return result, nil
 }
+
+// ExecuteIDs 通过 UID 列表直接获取文档摘要, 不依赖 WebEnv 及 query_key
+// UID 较多时 url 过长, 参数放在请求体中
+func (s *ESummaryOperation) ExecuteIDs(ctx context.Context, ids []string, query *types.Query) (*types.ESummaryResult, error) {
+	params := s.newParameters()
+	params.Set("id", strings.Join(ids, ","))
+
+	logcdl.Debug("esummary %d ids for query '%v'", len(ids), query)
+
+	body, err := s.doRequest(ctx, "POST", s.BaseURL, params)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "esummary http request failed for query '%v'", query)
+	}
+
+	parser, err := response.NewESummaryResponseParser(response.ParserType(s.GetRetMode()))
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.ParseESummary(body)
+}
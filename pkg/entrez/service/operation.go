@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	customeHttp "github.com/iEchoxu/clinvarDL/pkg/entrez/http"
@@ -19,15 +18,20 @@ const (
 	BaseURLESearch  = baseURL + "esearch.fcgi"
 	BaseURLEPost    = baseURL + "epost.fcgi"
 	BaseURLESummary = baseURL + "esummary.fcgi"
+	BaseURLEFetch   = baseURL + "efetch.fcgi"
+	BaseURLELink    = baseURL + "elink.fcgi"
 )
 
 // BaseOperation 包含所有 Entrez 操作共享的属性和方法
+// Parameters 是所有请求共用的参数模板, 只在创建操作时由 SetX 方法设置
+// 每次请求通过 newParameters 复制一份独立的参数, 并发请求之间不会相互覆盖 term、retstart 等参数
 type BaseOperation struct {
-	BaseURL     string
-	Parameters  url.Values
-	RateLimiter *customeHttp.RateLimiter
-	useStream   bool
-	httpClient  *customeHttp.Client
+	BaseURL         string
+	Parameters      url.Values
+	RateLimiter     *customeHttp.RateLimiter
+	useStream       bool
+	maxResponseSize int64 // 响应体最大字节数, 0 表示不限制
+	httpClient      *customeHttp.Client
 }
 
 // NewBaseOperation 创建基础操作实例
@@ -115,19 +119,42 @@ func (b *BaseOperation) SetUseStream(useStream bool) *BaseOperation {
 	return b
 }
 
+// SetMaxResponseSize 设置响应体最大字节数, 超过时中止读取并返回 ErrResponseTooLarge
+func (b *BaseOperation) SetMaxResponseSize(size int64) *BaseOperation {
+	if size > 0 {
+		b.maxResponseSize = size
+	}
+	return b
+}
+
 func (b *BaseOperation) GetRetMode() string {
 	return b.Parameters.Get("retmode")
 }
 
-// BuildURL 构建完整的 URL
+// BuildURL 使用参数模板构建完整的 URL
 func (b *BaseOperation) BuildURL() (*url.URL, error) {
+	return b.buildURL(b.Parameters)
+}
+
+// newParameters 以参数模板为基础创建单次请求的参数
+// 值切片也会被复制, 对返回值的任何修改都不会影响参数模板及其他请求
+func (b *BaseOperation) newParameters() url.Values {
+	params := make(url.Values, len(b.Parameters))
+	for k, v := range b.Parameters {
+		params[k] = append([]string(nil), v...)
+	}
+	return params
+}
+
+// buildURL 使用单次请求的参数构建完整的 URL
+func (b *BaseOperation) buildURL(params url.Values) (*url.URL, error) {
 	u, err := url.Parse(b.BaseURL)
 	if err != nil {
 		return nil, errors.Wrapf(customerrors.ErrURL, "failed to parse base url: %v", err)
 	}
 
 	q := u.Query()
-	for k, v := range b.Parameters {
+	for k, v := range params {
 		q[k] = v
 	}
 	u.RawQuery = q.Encode()
@@ -204,10 +231,9 @@ func (b *BaseOperation) handleResponse(resp *http.Response) ([]byte, error) {
 		return nil, customerrors.NewHTTPError(customerrors.WithStatusCode(resp.StatusCode))
 	}
 
-	// TODO: 数据量过多时采用流式读取
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(b.limitBody(resp.Body))
 	if err != nil {
-		return nil, customerrors.NewNetError("failed to read response body", err) // 读取响应体失败直接返回网络错误类型
+		return nil, err
 	}
 
 	if len(body) == 0 {
@@ -217,36 +243,33 @@ func (b *BaseOperation) handleResponse(resp *http.Response) ([]byte, error) {
 	return body, nil
 }
 
-// streamResponse 流式处理 HTTP 响应
-func (b *BaseOperation) streamResponse(resp *http.Response, handler func(chunk []byte) error) error {
-	// 使用 bufio.Reader 进行缓冲读取
-	reader := bufio.NewReaderSize(resp.Body, 128*1024) // 128KB 缓冲区
-
-	buffer := make([]byte, 128*1024) // 128KB 缓冲区
-
-	for {
-		n, err := reader.Read(buffer)
-		if n > 0 {
-			// 处理当前读取的数据块
-			chunk := buffer[:n]
-			if err := handler(chunk); err != nil {
-				return errors.Wrap(err, "failed to handle response chunk")
-			}
-		}
+// limitBody 限制响应体的读取大小
+// 读取响应体失败返回网络错误 (可重试); 超过 maxResponseSize 返回 ErrResponseTooLarge (不可重试, 重试得到的响应大小相同)
+func (b *BaseOperation) limitBody(body io.Reader) io.Reader {
+	return &limitedBody{reader: body, limit: b.maxResponseSize}
+}
 
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return customerrors.NewNetError("failed to read response body", err)
-		}
-	}
+// limitedBody 限制读取大小的响应体
+type limitedBody struct {
+	reader io.Reader
+	limit  int64 // 0 表示不限制
+	read   int64
+}
 
-	return nil
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.limit > 0 && l.read > l.limit {
+		return n, errors.Wrapf(customerrors.ErrResponseTooLarge, "response body exceeds %d MB", l.limit>>20)
+	}
+	if err != nil && err != io.EOF {
+		return n, customerrors.NewNetError("failed to read response body", err) // 读取响应体失败直接返回网络错误类型
+	}
+	return n, err
 }
 
-// doStreamRequest 执行流式 HTTP 请求
-func (b *BaseOperation) doStreamRequest(ctx context.Context, method, url string, params url.Values, handler func(chunk []byte) error) error {
+// doStreamRequest 执行流式 HTTP 请求, handler 边读取边处理响应体, 不缓存完整的响应
+func (b *BaseOperation) doStreamRequest(ctx context.Context, method, url string, params url.Values, handler func(body io.Reader) error) error {
 	// 检查 context 是否已取消
 	if ctx.Err() != nil {
 		return customerrors.NewTimeoutError(
@@ -282,5 +305,5 @@ func (b *BaseOperation) doStreamRequest(ctx context.Context, method, url string,
 	}
 
 	// 流式处理响应
-	return b.streamResponse(resp, handler)
+	return handler(b.limitBody(resp.Body))
 }
@@ -0,0 +1,259 @@
+package response
+
+import (
+	"bytes"
+	"testing"
+
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/pkg/errors"
+)
+
+// eutilsErrorCase NCBI 以 HTTP 200 返回的错误响应及期望的错误类别
+type eutilsErrorCase struct {
+	name    string
+	parser  ParserType
+	op      string // esearch、esummary、esummary stream 或 epost
+	body    string
+	kind    customerrors.EutilsErrorKind
+	expired bool   // 是否为历史会话过期错误
+	retry   bool   // 是否可以重试
+	msg     string // 错误信息, 与 NCBI 返回的内容一致
+}
+
+var eutilsErrorCases = []eutilsErrorCase{
+	{
+		name:   "esearch empty term",
+		parser: ParserXML,
+		op:     "esearch",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><!DOCTYPE eSearchResult PUBLIC "-//NLM//DTD esearch 20060628//EN" "https://eutils.ncbi.nlm.nih.gov/eutils/dtd/20060628/esearch.dtd"><eSearchResult><ERROR>Empty term and query_key - nothing todo</ERROR></eSearchResult>`,
+		kind:   customerrors.EutilsBadTerm,
+		msg:    "Empty term and query_key - nothing todo",
+	},
+	{
+		name:   "esearch backend failed",
+		parser: ParserXML,
+		op:     "esearch",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><eSearchResult><ERROR>Search Backend failed: Couldn't resolve #exLinkSrv2, the address table is empty.</ERROR></eSearchResult>`,
+		kind:   customerrors.EutilsBackend,
+		retry:  true,
+		msg:    "Search Backend failed: Couldn't resolve #exLinkSrv2, the address table is empty.",
+	},
+	{
+		name:   "esearch bare error",
+		parser: ParserXML,
+		op:     "esearch",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><ERROR>Invalid db name specified: clinvarx</ERROR>`,
+		kind:   customerrors.EutilsBadRequest,
+		msg:    "Invalid db name specified: clinvarx",
+	},
+	{
+		name:   "esearch field not found",
+		parser: ParserXML,
+		op:     "esearch",
+		body: `<?xml version="1.0" encoding="UTF-8" ?><eSearchResult><Count>0</Count><RetMax>0</RetMax><RetStart>0</RetStart><IdList/><TranslationSet/>` +
+			`<QueryTranslation>(BRCA1[genesymbol])</QueryTranslation><ErrorList><FieldNotFound>genesymbol</FieldNotFound></ErrorList>` +
+			`<WarningList><OutputMessage>No items found.</OutputMessage></WarningList></eSearchResult>`,
+		kind: customerrors.EutilsUnknownField,
+		msg:  "field not found: genesymbol",
+	},
+	{
+		name:   "esearch empty term",
+		parser: ParserJSON,
+		op:     "esearch",
+		body:   `{"header":{"type":"esearch","version":"0.3"},"esearchresult":{"ERROR":"Empty term and query_key - nothing todo"}}`,
+		kind:   customerrors.EutilsBadTerm,
+		msg:    "Empty term and query_key - nothing todo",
+	},
+	{
+		name:   "esearch rate limit",
+		parser: ParserJSON,
+		op:     "esearch",
+		body:   `{"error":"API rate limit exceeded","api-key":"203.0.113.7","count":"11","limit":"10"}`,
+		kind:   customerrors.EutilsBackend,
+		retry:  true,
+		msg:    "API rate limit exceeded",
+	},
+	{
+		name:   "esearch field not found",
+		parser: ParserJSON,
+		op:     "esearch",
+		body: `{"header":{"type":"esearch","version":"0.3"},"esearchresult":{"count":"0","retmax":"0","retstart":"0","idlist":[],"translationset":[],` +
+			`"querytranslation":"(BRCA1[genesymbol])","errorlist":{"phrasesnotfound":[],"fieldsnotfound":["genesymbol"]},` +
+			`"warninglist":{"phrasesignored":[],"quotedphrasesnotfound":[],"outputmessages":["No items found."]}}}`,
+		kind: customerrors.EutilsUnknownField,
+		msg:  "field not found: genesymbol",
+	},
+	{
+		name:    "esummary history expired",
+		parser:  ParserXML,
+		op:      "esummary",
+		body:    `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><ERROR>Unable to obtain query #1</ERROR></eSummaryResult>`,
+		expired: true,
+	},
+	{
+		name:   "esummary invalid db",
+		parser: ParserXML,
+		op:     "esummary",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><ERROR>Invalid db name specified: clinvarx</ERROR></eSummaryResult>`,
+		kind:   customerrors.EutilsBadRequest,
+		msg:    "Invalid db name specified: clinvarx",
+	},
+	{
+		name:   "esummary empty document summary set",
+		parser: ParserXML,
+		op:     "esummary",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><DocumentSummarySet status="OK"><DbBuild>Build240601-1015.1</DbBuild></DocumentSummarySet></eSummaryResult>`,
+		kind:   customerrors.EutilsEmptyResult,
+		retry:  true,
+	},
+	{
+		name:    "esummary history expired",
+		parser:  ParserXML,
+		op:      "esummary stream",
+		body:    `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><ERROR>Unable to obtain query #1</ERROR></eSummaryResult>`,
+		expired: true,
+	},
+	{
+		name:   "esummary backend failed",
+		parser: ParserXML,
+		op:     "esummary stream",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><ERROR>Search Backend failed: read timeout</ERROR></eSummaryResult>`,
+		kind:   customerrors.EutilsBackend,
+		retry:  true,
+		msg:    "Search Backend failed: read timeout",
+	},
+	{
+		name:   "esummary bare error",
+		parser: ParserXML,
+		op:     "esummary stream",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><ERROR>Invalid db name specified: clinvarx</ERROR>`,
+		kind:   customerrors.EutilsBadRequest,
+		msg:    "Invalid db name specified: clinvarx",
+	},
+	{
+		name:   "esummary empty document summary set",
+		parser: ParserXML,
+		op:     "esummary stream",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><eSummaryResult><DocumentSummarySet status="OK"><DbBuild>Build240601-1015.1</DbBuild></DocumentSummarySet></eSummaryResult>`,
+		kind:   customerrors.EutilsEmptyResult,
+		retry:  true,
+	},
+	{
+		name:    "esummary history expired",
+		parser:  ParserJSON,
+		op:      "esummary",
+		body:    `{"header":{"type":"esummary","version":"0.3"},"esummaryresult":["Unable to obtain query #1"]}`,
+		expired: true,
+	},
+	{
+		name:   "esummary invalid db",
+		parser: ParserJSON,
+		op:     "esummary",
+		body:   `{"header":{"type":"esummary","version":"0.3"},"esummaryresult":["Invalid db name specified: clinvarx"]}`,
+		kind:   customerrors.EutilsBadRequest,
+		msg:    "Invalid db name specified: clinvarx",
+	},
+	{
+		name:   "esummary rate limit",
+		parser: ParserJSON,
+		op:     "esummary",
+		body:   `{"error":"API rate limit exceeded","api-key":"203.0.113.7","count":"11","limit":"10"}`,
+		kind:   customerrors.EutilsBackend,
+		retry:  true,
+		msg:    "API rate limit exceeded",
+	},
+	{
+		name:   "esummary empty result",
+		parser: ParserJSON,
+		op:     "esummary",
+		body:   `{"header":{"type":"esummary","version":"0.3"},"result":{"uids":[]}}`,
+		kind:   customerrors.EutilsEmptyResult,
+		retry:  true,
+	},
+	{
+		name:   "epost invalid db",
+		parser: ParserXML,
+		op:     "epost",
+		body:   `<?xml version="1.0" encoding="UTF-8" ?><ePostResult><ERROR>Invalid db name specified: clinvarx</ERROR></ePostResult>`,
+		kind:   customerrors.EutilsBadRequest,
+		msg:    "Invalid db name specified: clinvarx",
+	},
+}
+
+// parseEutilsError 使用指定的解析器解析响应, 返回解析错误
+func parseEutilsError(t *testing.T, c eutilsErrorCase) error {
+	t.Helper()
+
+	switch c.op {
+	case "esearch":
+		parser, err := NewESearchResponseParser(c.parser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = parser.ParseESearch([]byte(c.body))
+		return err
+	case "esummary":
+		parser, err := NewESummaryResponseParser(c.parser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = parser.ParseESummary([]byte(c.body))
+		return err
+	case "esummary stream":
+		parser, err := NewESummaryStreamParser(c.parser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parser.DecodeESummary(bytes.NewReader([]byte(c.body)), func(*types.DocumentSummary) error { return nil })
+	case "epost":
+		parser, err := NewEPostResponseParser(c.parser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = parser.ParseEPost([]byte(c.body))
+		return err
+	default:
+		t.Fatalf("unknown op %s", c.op)
+		return nil
+	}
+}
+
+func TestEutilsErrorClassification(t *testing.T) {
+	for _, c := range eutilsErrorCases {
+		t.Run(c.op+"/"+string(c.parser)+"/"+c.name, func(t *testing.T) {
+			err := parseEutilsError(t, c)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var retryErr customerrors.RetryableError
+			if !errors.As(err, &retryErr) {
+				t.Fatalf("error %T is not retryable-classified: %v", err, err)
+			}
+			if retryErr.ShouldRetry() != c.retry {
+				t.Errorf("ShouldRetry() = %v, want %v", retryErr.ShouldRetry(), c.retry)
+			}
+
+			var expiredErr *customerrors.HistoryExpiredError
+			if c.expired {
+				if !errors.As(err, &expiredErr) {
+					t.Errorf("got %T (%v), want history expired error", err, err)
+				}
+				return
+			}
+
+			var eutilsErr *customerrors.EutilsError
+			if !errors.As(err, &eutilsErr) {
+				t.Fatalf("got %T (%v), want eutils error", err, err)
+			}
+			if eutilsErr.Kind() != c.kind {
+				t.Errorf("kind = %v, want %v", eutilsErr.Kind(), c.kind)
+			}
+			if c.msg != "" && err.Error() != c.msg {
+				t.Errorf("message = %q, want %q", err.Error(), c.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response/xml"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/pkg/errors"
+)
+
+// ePostResponse 定义 EPost JSON 响应结构
+type ePostResponse struct {
+	QueryKey string `json:"querykey"`
+	WebEnv   string `json:"webenv"`
+	Error    string `json:"error"`
+}
+
+// EPostResponseParser JSON 格式的 EPost 响应解析器
+type EPostResponseParser struct{}
+
+// ParseEPost 解析 EPost 响应
+// EPost 不支持 retmode 参数, 始终返回 XML, 因此 XML 格式的响应交由 XML 解析器处理
+func (p *EPostResponseParser) ParseEPost(data []byte) (*types.EPostResult, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '<' {
+		return (&xml.EPostResponseParser{}).ParseEPost(data)
+	}
+
+	var response ePostResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "epost json unmarshal failed: %v", err)
+	}
+
+	if response.Error != "" {
+		return nil, customerrors.ClassifyEutilsError("epost", response.Error)
+	}
+
+	return &types.EPostResult{
+		QueryKey: response.QueryKey,
+		WebEnv:   response.WebEnv,
+	}, nil
+}
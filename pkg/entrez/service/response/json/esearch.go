@@ -0,0 +1,70 @@
+package json
+
+import (
+	"encoding/json"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// eSearchResponse 定义 ESearch JSON 响应结构
+type eSearchResponse struct {
+	ESearchResult struct {
+		Count    string   `json:"count"`
+		QueryKey string   `json:"querykey"`
+		WebEnv   string   `json:"webenv"`
+		IdList   []string `json:"idlist"`
+		Error    string   `json:"ERROR"`
+		ErrList  struct {
+			FieldsNotFound []string `json:"fieldsnotfound"`
+		} `json:"errorlist"`
+	} `json:"esearchresult"`
+	Error string `json:"error"`
+}
+
+// ESearchResponseParser JSON 格式的 ESearch 响应解析器
+type ESearchResponseParser struct{}
+
+// ParseESearch 解析 ESearch JSON 响应
+func (p *ESearchResponseParser) ParseESearch(data []byte) (*types.ESearchResult, error) {
+	var response eSearchResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "esearch json unmarshal failed: %v", err)
+	}
+
+	if msg := firstNonEmpty(response.Error, response.ESearchResult.Error); msg != "" {
+		return nil, customerrors.ClassifyEutilsError("esearch", msg)
+	}
+
+	var result types.ESearchResult
+	if response.ESearchResult.Count != "" {
+		count, err := strconv.Atoi(response.ESearchResult.Count)
+		if err != nil {
+			return nil, errors.Wrapf(customerrors.ErrParse, "invalid esearch count '%s': %v", response.ESearchResult.Count, err)
+		}
+		result.Count = count
+	}
+	// 检索式中的字段不存在且没有结果时, 重试结果相同
+	if result.Count == 0 && len(response.ESearchResult.ErrList.FieldsNotFound) > 0 {
+		return nil, customerrors.NewEutilsError("esearch", "field not found: "+strings.Join(response.ESearchResult.ErrList.FieldsNotFound, ", "))
+	}
+
+	result.IdList.Id = response.ESearchResult.IdList
+	result.QueryKey = response.ESearchResult.QueryKey
+	result.WebEnv = response.ESearchResult.WebEnv
+
+	return &result, nil
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+package json
+
+import (
+	"encoding/json"
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/pkg/errors"
+)
+
+// eSummaryResponse 定义 ESummary JSON 响应结构
+// result 中 uids 为 uid 列表, 其余键为 uid, 值为对应的文档摘要
+type eSummaryResponse struct {
+	Result map[string]json.RawMessage `json:"result"`
+	Error  string                     `json:"error"`
+	// 历史会话过期等错误时返回, 如: {"esummaryresult": ["Unable to obtain query #1"]}
+	Messages []string `json:"esummaryresult"`
+}
+
+// documentSummary 定义 JSON 格式的单个文档摘要
+type documentSummary struct {
+	Uid                          string         `json:"uid"`
+	Accession                    string         `json:"accession"`
+	AccessionVersion             string         `json:"accession_version"`
+	Title                        string         `json:"title"`
+	VariationSet                 []variation    `json:"variation_set"`
+	GermlineClassification       classification `json:"germline_classification"`
+	ClinicalImpactClassification classification `json:"clinical_impact_classification"`
+	OncogenicityClassification   classification `json:"oncogenicity_classification"`
+	GeneSort                     string         `json:"gene_sort"`
+	ChrSort                      string         `json:"chr_sort"`
+	LocationSort                 string         `json:"location_sort"`
+	Genes                        []gene         `json:"genes"`
+	MolecularConsequenceList     []string       `json:"molecular_consequence_list"`
+	ProteinChange                string         `json:"protein_change"`
+	Error                        string         `json:"error"`
+}
+
+// variation 定义 JSON 格式的变异信息
+type variation struct {
+	MeasureId      string     `json:"measure_id"`
+	VariationXrefs []xref     `json:"variation_xrefs"`
+	CdnaChange     string     `json:"cdna_change"`
+	VariationLoc   []assembly `json:"variation_loc"`
+	VariantType    string     `json:"variant_type"`
+	CanonicalSPDI  string     `json:"canonical_spdi"`
+}
+
+// xref 定义 JSON 格式的变异或特征引用
+type xref struct {
+	DBSource string `json:"db_source"`
+	DbId     string `json:"db_id"`
+}
+
+// assembly 定义 JSON 格式的基因组装信息
+type assembly struct {
+	Status            string `json:"status"`
+	AssemblyName      string `json:"assembly_name"`
+	Chr               string `json:"chr"`
+	Band              string `json:"band"`
+	Start             string `json:"start"`
+	Stop              string `json:"stop"`
+	DisplayStart      string `json:"display_start"`
+	DisplayStop       string `json:"display_stop"`
+	AssemblyAccVer    string `json:"assembly_acc_ver"`
+	AnnotationRelease string `json:"annotation_release"`
+}
+
+// classification 定义 JSON 格式的分类信息
+type classification struct {
+	Description   string  `json:"description"`
+	LastEvaluated string  `json:"last_evaluated"`
+	ReviewStatus  string  `json:"review_status"`
+	TraitSet      []trait `json:"trait_set"`
+}
+
+// trait 定义 JSON 格式的特征信息
+type trait struct {
+	TraitXrefs []xref `json:"trait_xrefs"`
+	TraitName  string `json:"trait_name"`
+}
+
+// gene 定义 JSON 格式的基因信息
+type gene struct {
+	Symbol string `json:"symbol"`
+	GeneID string `json:"geneid"`
+}
+
+// ESummaryResponseParser JSON 格式的 ESummary 响应解析器
+type ESummaryResponseParser struct{}
+
+// ParseESummary 解析 ESummary JSON 响应, 按 uids 的顺序生成与 XML 解析结果相同的结构
+func (h *ESummaryResponseParser) ParseESummary(data []byte) (*types.ESummaryResult, error) {
+	var response eSummaryResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "esummary json unmarshal failed: %v", err)
+	}
+
+	for _, msg := range append([]string{response.Error}, response.Messages...) {
+		if msg != "" {
+			return nil, customerrors.ClassifyEutilsError("esummary", msg)
+		}
+	}
+
+	var uids []string
+	if raw, ok := response.Result["uids"]; ok {
+		if err := json.Unmarshal(raw, &uids); err != nil {
+			return nil, errors.Wrapf(customerrors.ErrParse, "esummary json uids unmarshal failed: %v", err)
+		}
+	}
+
+	result := &types.ESummaryResult{}
+	for _, uid := range uids {
+		raw, ok := response.Result[uid]
+		if !ok {
+			continue
+		}
+
+		var doc documentSummary
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, errors.Wrapf(customerrors.ErrParse, "esummary json unmarshal failed for uid %s: %v", uid, err)
+		}
+		if doc.Uid == "" {
+			doc.Uid = uid
+		}
+
+		result.DocumentSummarySet.DocumentSummary = append(result.DocumentSummarySet.DocumentSummary, doc.toDocumentSummary())
+	}
+
+	if len(result.DocumentSummarySet.DocumentSummary) == 0 {
+		return nil, customerrors.NewEutilsEmptyResultError("esummary", "esummary returned an empty result")
+	}
+
+	return result, nil
+}
+
+// toDocumentSummary 转换为 XML 解析使用的文档摘要结构
+func (d *documentSummary) toDocumentSummary() *types.DocumentSummary {
+	doc := &types.DocumentSummary{
+		Uid:                          d.Uid,
+		Accession:                    d.Accession,
+		AccessionVersion:             d.AccessionVersion,
+		Title:                        d.Title,
+		GermlineClassification:       d.GermlineClassification.toClassification(),
+		ClinicalImpactClassification: d.ClinicalImpactClassification.toClassification(),
+		OncogenicityClassification:   d.OncogenicityClassification.toClassification(),
+		GeneSort:                     d.GeneSort,
+		ChrSort:                      d.ChrSort,
+		LocationSort:                 d.LocationSort,
+		ProteinChange:                d.ProteinChange,
+		Error:                        d.Error,
+	}
+
+	// XML 中每条记录只有一个 variation 节点
+	if len(d.VariationSet) > 0 {
+		doc.VariationSet.Variation = d.VariationSet[0].toVariation()
+	}
+
+	for _, g := range d.Genes {
+		doc.Genes.Gene = append(doc.Genes.Gene, types.Gene{Symbol: g.Symbol, GeneID: g.GeneID})
+	}
+
+	if len(d.MolecularConsequenceList) > 0 {
+		doc.MolecularConsequenceList.String = d.MolecularConsequenceList
+	}
+
+	return doc
+}
+
+// toVariation 转换为 XML 解析使用的变异结构
+func (v *variation) toVariation() types.Variation {
+	result := types.Variation{
+		MeasureId:     v.MeasureId,
+		CdnaChange:    v.CdnaChange,
+		VariantType:   v.VariantType,
+		CanonicalSPDI: v.CanonicalSPDI,
+	}
+
+	for _, x := range v.VariationXrefs {
+		result.VariationXrefs.VariationXref = append(result.VariationXrefs.VariationXref,
+			types.VariationXref{DBSource: x.DBSource, DbId: x.DbId})
+	}
+
+	for _, a := range v.VariationLoc {
+		result.VariationLoc.AssemblySet = append(result.VariationLoc.AssemblySet, types.Assembly(a))
+	}
+
+	return result
+}
+
+// toClassification 转换为 XML 解析使用的分类结构
+func (c *classification) toClassification() types.Classification {
+	result := types.Classification{
+		Description:   c.Description,
+		LastEvaluated: c.LastEvaluated,
+		ReviewStatus:  c.ReviewStatus,
+	}
+
+	for _, t := range c.TraitSet {
+		info := types.TraitInfo{Name: t.TraitName}
+		for _, x := range t.TraitXrefs {
+			info.TraitXrefs.TraitXref = append(info.TraitXrefs.TraitXref, types.TraitXref{DBSource: x.DBSource, DbId: x.DbId})
+		}
+		result.TraitSet.Trait = append(result.TraitSet.Trait, info)
+	}
+
+	return result
+}
@@ -0,0 +1,138 @@
+package response
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+)
+
+// readTestdata 读取 testdata 中按 NCBI E-utilities 响应格式保存的样例
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read testdata %s: %v", name, err)
+	}
+	return data
+}
+
+func TestESearchParserParity(t *testing.T) {
+	xmlParser, err := NewESearchResponseParser(ParserXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonParser, err := NewESearchResponseParser(ParserJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromXML, err := xmlParser.ParseESearch(readTestdata(t, "esearch.xml"))
+	if err != nil {
+		t.Fatalf("parse esearch xml: %v", err)
+	}
+	fromJSON, err := jsonParser.ParseESearch(readTestdata(t, "esearch.json"))
+	if err != nil {
+		t.Fatalf("parse esearch json: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromXML, fromJSON) {
+		t.Errorf("esearch results differ:\nxml:  %+v\njson: %+v", fromXML, fromJSON)
+	}
+	if fromXML.Count != 2 || len(fromXML.IdList.Id) != 2 || fromXML.QueryKey == "" || fromXML.WebEnv == "" {
+		t.Errorf("unexpected esearch result: %+v", fromXML)
+	}
+}
+
+func TestESummaryParserParity(t *testing.T) {
+	xmlParser, err := NewESummaryResponseParser(ParserXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonParser, err := NewESummaryResponseParser(ParserJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromXML, err := xmlParser.ParseESummary(readTestdata(t, "esummary.xml"))
+	if err != nil {
+		t.Fatalf("parse esummary xml: %v", err)
+	}
+	fromJSON, err := jsonParser.ParseESummary(readTestdata(t, "esummary.json"))
+	if err != nil {
+		t.Fatalf("parse esummary json: %v", err)
+	}
+
+	xmlDocs := fromXML.DocumentSummarySet.DocumentSummary
+	jsonDocs := fromJSON.DocumentSummarySet.DocumentSummary
+	if len(xmlDocs) != 2 || len(jsonDocs) != len(xmlDocs) {
+		t.Fatalf("document count: xml %d, json %d", len(xmlDocs), len(jsonDocs))
+	}
+	for i := range xmlDocs {
+		if !reflect.DeepEqual(xmlDocs[i], jsonDocs[i]) {
+			t.Errorf("document %s differs:\nxml:  %+v\njson: %+v", xmlDocs[i].Uid, xmlDocs[i], jsonDocs[i])
+		}
+	}
+}
+
+func TestESummaryStreamParserParity(t *testing.T) {
+	parser, err := NewESummaryResponseParser(ParserXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamParser, err := NewESummaryStreamParser(ParserXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := readTestdata(t, "esummary.xml")
+	parsed, err := parser.ParseESummary(data)
+	if err != nil {
+		t.Fatalf("parse esummary xml: %v", err)
+	}
+
+	var decoded types.ESummaryResult
+	err = streamParser.DecodeESummary(bytes.NewReader(data), func(doc *types.DocumentSummary) error {
+		decoded.DocumentSummarySet.DocumentSummary = append(decoded.DocumentSummarySet.DocumentSummary, doc)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("decode esummary xml: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed, &decoded) {
+		t.Errorf("streamed esummary differs from parsed result")
+	}
+}
+
+// EPost 不支持 retmode 参数, retmode=json 时 NCBI 同样返回 XML, 两种解析器读取同一响应
+func TestEPostParserParity(t *testing.T) {
+	xmlParser, err := NewEPostResponseParser(ParserXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonParser, err := NewEPostResponseParser(ParserJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := readTestdata(t, "epost.xml")
+	fromXML, err := xmlParser.ParseEPost(data)
+	if err != nil {
+		t.Fatalf("parse epost with xml parser: %v", err)
+	}
+	fromJSON, err := jsonParser.ParseEPost(data)
+	if err != nil {
+		t.Fatalf("parse epost with json parser: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromXML, fromJSON) {
+		t.Errorf("epost results differ:\nxml:  %+v\njson: %+v", fromXML, fromJSON)
+	}
+	if fromXML.QueryKey != "1" || fromXML.WebEnv == "" {
+		t.Errorf("unexpected epost result: %+v", fromXML)
+	}
+}
@@ -2,8 +2,10 @@ package response
 
 import (
 	"fmt"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response/json"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/service/response/xml"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"io"
 )
 
 // ESearchResponseParser 定义 ESearch 响应解析器接口
@@ -16,11 +18,32 @@ type ESummaryResponseParser interface {
 	ParseESummary(data []byte) (*types.ESummaryResult, error)
 }
 
+// ESummaryStreamParser 定义 ESummary 流式响应解析器接口
+// 边读取边解析响应, 每解析出一个文档摘要即交给 handler, 不缓存完整的响应
+type ESummaryStreamParser interface {
+	DecodeESummary(r io.Reader, handler func(doc *types.DocumentSummary) error) error
+}
+
 // EPostResponseParser 定义 EPost 响应解析器接口
 type EPostResponseParser interface {
 	ParseEPost(data []byte) (*types.EPostResult, error)
 }
 
+// EFetchResponseParser 定义 EFetch 响应解析器接口
+type EFetchResponseParser interface {
+	ParseEFetch(data []byte) (*types.EFetchResult, error)
+}
+
+// ELinkResponseParser 定义 ELink 响应解析器接口
+type ELinkResponseParser interface {
+	ParseELink(data []byte) (*types.ELinkResult, error)
+}
+
+// MedGenSummaryResponseParser 定义 MedGen ESummary 响应解析器接口
+type MedGenSummaryResponseParser interface {
+	ParseMedGenSummary(data []byte) (*types.MedGenSummaryResult, error)
+}
+
 // ParserType 定义解析器类型
 type ParserType string
 
@@ -35,7 +58,7 @@ func NewESearchResponseParser(parserType ParserType) (ESearchResponseParser, err
 	case ParserXML:
 		return &xml.ESearchResponseParser{}, nil
 	case ParserJSON:
-		return nil, fmt.Errorf("JSON parser not implemented yet")
+		return &json.ESearchResponseParser{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported parser type: %s", parserType)
 	}
@@ -47,20 +70,62 @@ func NewESummaryResponseParser(parserType ParserType) (ESummaryResponseParser, e
 	case ParserXML:
 		return &xml.ESummaryResponseParser{}, nil
 	case ParserJSON:
-		return nil, fmt.Errorf("JSON parser not implemented yet")
+		return &json.ESummaryResponseParser{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported parser type: %s", parserType)
 	}
 }
 
+// NewESummaryStreamParser 根据类型创建 ESummary 流式响应解析器
+// 只支持 XML 格式
+func NewESummaryStreamParser(parserType ParserType) (ESummaryStreamParser, error) {
+	switch parserType {
+	case ParserXML:
+		return &xml.ESummaryResponseParser{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported parser type for esummary stream: %s", parserType)
+	}
+}
+
 // NewEPostResponseParser 根据类型创建 EPost 响应解析器
 func NewEPostResponseParser(parserType ParserType) (EPostResponseParser, error) {
 	switch parserType {
 	case ParserXML:
 		return &xml.EPostResponseParser{}, nil
 	case ParserJSON:
-		return nil, fmt.Errorf("JSON parser not implemented yet")
+		return &json.EPostResponseParser{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported parser type: %s", parserType)
 	}
 }
+
+// NewEFetchResponseParser 根据类型创建 EFetch 响应解析器
+// rettype=vcv 只支持 XML 格式
+func NewEFetchResponseParser(parserType ParserType) (EFetchResponseParser, error) {
+	switch parserType {
+	case ParserXML:
+		return &xml.EFetchResponseParser{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported parser type for efetch vcv records: %s", parserType)
+	}
+}
+
+// NewELinkResponseParser 根据类型创建 ELink 响应解析器
+func NewELinkResponseParser(parserType ParserType) (ELinkResponseParser, error) {
+	switch parserType {
+	case ParserXML:
+		return &xml.ELinkResponseParser{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported parser type for elink: %s", parserType)
+	}
+}
+
+// NewMedGenSummaryResponseParser 根据类型创建 MedGen ESummary 响应解析器
+func NewMedGenSummaryResponseParser(parserType ParserType) (MedGenSummaryResponseParser, error) {
+	switch parserType {
+	case ParserXML:
+		return &xml.MedGenSummaryResponseParser{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported parser type for medgen esummary: %s", parserType)
+	}
+}
@@ -0,0 +1,196 @@
+package xml
+
+import (
+	"encoding/xml"
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+)
+
+// vcvResultSet 定义 efetch rettype=vcv 的 XML 响应结构
+type vcvResultSet struct {
+	Error            string             `xml:"ERROR"`
+	VariationArchive []variationArchive `xml:"VariationArchive"`
+}
+
+// variationArchive 定义单个 VCV 记录
+// 2024 年起 ClinVar 使用 ClassifiedRecord, 之前的版本使用 InterpretedRecord, 两者结构相同
+type variationArchive struct {
+	VariationID         string         `xml:"VariationID,attr"`
+	Accession           string         `xml:"Accession,attr"`
+	Version             string         `xml:"Version,attr"`
+	NumberOfSubmitters  string         `xml:"NumberOfSubmitters,attr"`
+	NumberOfSubmissions string         `xml:"NumberOfSubmissions,attr"`
+	ClassifiedRecord    *vcvRecordBody `xml:"ClassifiedRecord"`
+	InterpretedRecord   *vcvRecordBody `xml:"InterpretedRecord"`
+}
+
+// vcvRecordBody 定义 VCV 记录的主体
+type vcvRecordBody struct {
+	SimpleAllele struct {
+		HGVS []struct {
+			NucleotideExpression vcvExpression `xml:"NucleotideExpression"`
+			ProteinExpression    vcvExpression `xml:"ProteinExpression"`
+		} `xml:"HGVSlist>HGVS"`
+	} `xml:"SimpleAllele"`
+	ClinicalAssertions []clinicalAssertion `xml:"ClinicalAssertionList>ClinicalAssertion"`
+}
+
+// vcvExpression 定义 HGVS 表达式
+type vcvExpression struct {
+	Expression string `xml:"Expression"`
+}
+
+// clinicalAssertion 定义单个 SCV 提交
+type clinicalAssertion struct {
+	ClinVarAccession struct {
+		Accession     string `xml:"Accession,attr"`
+		SubmitterName string `xml:"SubmitterName,attr"`
+	} `xml:"ClinVarAccession"`
+	// 新版本结构
+	Classification struct {
+		DateLastEvaluated      string        `xml:"DateLastEvaluated,attr"`
+		ReviewStatus           string        `xml:"ReviewStatus"`
+		GermlineClassification string        `xml:"GermlineClassification"`
+		SomaticClinicalImpact  string        `xml:"SomaticClinicalImpact"`
+		Oncogenicity           string        `xml:"OncogenicityClassification"`
+		Citations              []vcvCitation `xml:"Citation"`
+	} `xml:"Classification"`
+	// 旧版本结构
+	ReviewStatus   string `xml:"ReviewStatus"`
+	Interpretation struct {
+		DateLastEvaluated string        `xml:"DateLastEvaluated,attr"`
+		Description       string        `xml:"Description"`
+		Citations         []vcvCitation `xml:"Citation"`
+	} `xml:"Interpretation"`
+	AttributeSets []struct {
+		Attribute struct {
+			Type  string `xml:"Type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Attribute"`
+		Citations []vcvCitation `xml:"Citation"`
+	} `xml:"AttributeSet"`
+	Citations         []vcvCitation `xml:"Citation"`
+	ObservedCitations []vcvCitation `xml:"ObservedInList>ObservedIn>ObservedData>Citation"`
+}
+
+// vcvCitation 定义文献引用
+type vcvCitation struct {
+	IDs []struct {
+		Source string `xml:"Source,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"ID"`
+}
+
+// EFetchResponseParser XML 格式的 EFetch (rettype=vcv) 响应解析器
+type EFetchResponseParser struct{}
+
+// ParseEFetch 解析 EFetch VCV XML 响应
+func (p *EFetchResponseParser) ParseEFetch(data []byte) (*types.EFetchResult, error) {
+	var resultSet vcvResultSet
+	if err := xml.Unmarshal(data, &resultSet); err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "efetch xml unmarshal failed: %v", err)
+	}
+
+	if msg := responseError(resultSet.Error, data); msg != "" {
+		return nil, customerrors.ClassifyEutilsError("efetch", msg)
+	}
+
+	result := &types.EFetchResult{}
+	for _, archive := range resultSet.VariationArchive {
+		result.Records = append(result.Records, archive.toRecord())
+	}
+
+	return result, nil
+}
+
+// toRecord 转换为 VCV 记录
+func (a *variationArchive) toRecord() *types.VCVRecord {
+	record := &types.VCVRecord{
+		VariationID: a.VariationID,
+		Accession:   a.Accession,
+		Version:     a.Version,
+	}
+	record.NumberOfSubmitters, _ = strconv.Atoi(a.NumberOfSubmitters)
+	record.NumberOfSubmissions, _ = strconv.Atoi(a.NumberOfSubmissions)
+
+	body := a.ClassifiedRecord
+	if body == nil {
+		body = a.InterpretedRecord
+	}
+	if body == nil {
+		return record
+	}
+
+	for _, hgvs := range body.SimpleAllele.HGVS {
+		for _, expression := range []string{hgvs.NucleotideExpression.Expression, hgvs.ProteinExpression.Expression} {
+			if expression = strings.TrimSpace(expression); expression != "" {
+				record.HGVS = append(record.HGVS, expression)
+			}
+		}
+	}
+
+	for _, assertion := range body.ClinicalAssertions {
+		record.Submissions = append(record.Submissions, assertion.toSubmission())
+	}
+
+	return record
+}
+
+// toSubmission 转换为 SCV 提交, 兼容新旧两种结构
+func (c *clinicalAssertion) toSubmission() types.Submission {
+	submission := types.Submission{
+		Accession:         c.ClinVarAccession.Accession,
+		Submitter:         c.ClinVarAccession.SubmitterName,
+		Classification:    firstNonEmpty(c.Classification.GermlineClassification, c.Classification.SomaticClinicalImpact, c.Classification.Oncogenicity, c.Interpretation.Description),
+		ReviewStatus:      firstNonEmpty(c.Classification.ReviewStatus, c.ReviewStatus),
+		DateLastEvaluated: firstNonEmpty(c.Classification.DateLastEvaluated, c.Interpretation.DateLastEvaluated),
+	}
+
+	citations := make([]vcvCitation, 0, len(c.Citations))
+	citations = append(citations, c.Citations...)
+	citations = append(citations, c.Classification.Citations...)
+	citations = append(citations, c.Interpretation.Citations...)
+	citations = append(citations, c.ObservedCitations...)
+
+	for _, attributeSet := range c.AttributeSets {
+		if attributeSet.Attribute.Type == "AssertionMethod" {
+			if criteria := strings.TrimSpace(attributeSet.Attribute.Value); criteria != "" {
+				submission.AssertionCriteria = append(submission.AssertionCriteria, criteria)
+			}
+			continue // 判定标准的引用不属于变异相关文献
+		}
+		citations = append(citations, attributeSet.Citations...)
+	}
+
+	seen := make(map[string]struct{})
+	for _, citation := range citations {
+		for _, id := range citation.IDs {
+			pmid := strings.TrimSpace(id.Value)
+			if id.Source != "PubMed" || pmid == "" {
+				continue
+			}
+			if _, ok := seen[pmid]; ok {
+				continue
+			}
+			seen[pmid] = struct{}{}
+			submission.Citations = append(submission.Citations, pmid)
+		}
+	}
+
+	return submission
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package xml
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+
+	"github.com/pkg/errors"
+
+	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
+)
+
+// eLinkResult 定义 elink cmd=neighbor 的 XML 响应结构
+// 每个 id 参数对应一个 LinkSet
+type eLinkResult struct {
+	Error    string `xml:"ERROR"`
+	LinkSets []struct {
+		Error      string   `xml:"ERROR"`
+		IDs        []string `xml:"IdList>Id"`
+		LinkSetDbs []struct {
+			DbTo     string   `xml:"DbTo"`
+			LinkName string   `xml:"LinkName"`
+			Links    []string `xml:"Link>Id"`
+		} `xml:"LinkSetDb"`
+	} `xml:"LinkSet"`
+}
+
+// ELinkResponseParser XML 格式的 ELink 响应解析器
+type ELinkResponseParser struct{}
+
+// ParseELink 解析 ELink XML 响应, 合并同一目标数据库下所有 LinkName 的关联 UID
+func (p *ELinkResponseParser) ParseELink(data []byte) (*types.ELinkResult, error) {
+	var resp eLinkResult
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "elink xml unmarshal failed: %v", err)
+	}
+
+	if msg := responseError(resp.Error, data); msg != "" {
+		return nil, customerrors.ClassifyEutilsError("elink", msg)
+	}
+
+	result := &types.ELinkResult{Links: make(map[string][]string)}
+	for _, linkSet := range resp.LinkSets {
+		if len(linkSet.IDs) == 0 {
+			continue
+		}
+
+		uid := strings.TrimSpace(linkSet.IDs[0])
+		seen := make(map[string]struct{})
+		for _, linkSetDb := range linkSet.LinkSetDbs {
+			if result.DbTo == "" {
+				result.DbTo = linkSetDb.DbTo
+			}
+			for _, link := range linkSetDb.Links {
+				if link = strings.TrimSpace(link); link == "" {
+					continue
+				}
+				if _, ok := seen[link]; ok {
+					continue
+				}
+				seen[link] = struct{}{}
+				result.Links[uid] = append(result.Links[uid], link)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// medGenSummaryResult 定义 MedGen esummary 的 XML 响应结构, 只解析概念 ID
+type medGenSummaryResult struct {
+	Error     string `xml:"ERROR"`
+	Summaries []struct {
+		Uid       string `xml:"uid,attr"`
+		ConceptId string `xml:"ConceptId"`
+	} `xml:"DocumentSummarySet>DocumentSummary"`
+}
+
+// MedGenSummaryResponseParser XML 格式的 MedGen ESummary 响应解析器
+type MedGenSummaryResponseParser struct{}
+
+// ParseMedGenSummary 解析 MedGen ESummary XML 响应
+func (p *MedGenSummaryResponseParser) ParseMedGenSummary(data []byte) (*types.MedGenSummaryResult, error) {
+	var resp medGenSummaryResult
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return nil, errors.Wrapf(customerrors.ErrParse, "medgen esummary xml unmarshal failed: %v", err)
+	}
+
+	if msg := responseError(resp.Error, data); msg != "" {
+		return nil, customerrors.ClassifyEutilsError("medgen esummary", msg)
+	}
+
+	result := &types.MedGenSummaryResult{Concepts: make(map[string]string, len(resp.Summaries))}
+	for _, summary := range resp.Summaries {
+		if conceptId := strings.TrimSpace(summary.ConceptId); conceptId != "" {
+			result.Concepts[strings.TrimSpace(summary.Uid)] = conceptId
+		}
+	}
+
+	return result, nil
+}
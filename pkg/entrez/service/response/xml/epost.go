@@ -15,5 +15,9 @@ func (p *EPostResponseParser) ParseEPost(data []byte) (*types.EPostResult, error
 	if err := xml.Unmarshal(data, &result); err != nil {
 		return nil, errors.Wrapf(customerrors.ErrParse, "epost xml unmarshal failed: %v", err)
 	}
+
+	if msg := responseError(result.Error, data); msg != "" {
+		return nil, customerrors.ClassifyEutilsError("epost", msg)
+	}
 	return &result, nil
 }
@@ -0,0 +1,30 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// responseError 返回响应中的错误信息
+// 错误信息通常包含在结果元素中 (如 <eSearchResult><ERROR>...</ERROR></eSearchResult>), 由 nested 传入;
+// 部分请求参数错误时整个响应只有一个 <ERROR> 元素, 如: <ERROR>Invalid db name specified: clinvarx</ERROR>
+func responseError(nested string, data []byte) string {
+	if nested != "" {
+		return nested
+	}
+
+	if !bytes.Contains(data, []byte("<ERROR>")) {
+		return ""
+	}
+
+	var root struct {
+		XMLName xml.Name
+		Message string `xml:",chardata"`
+	}
+	if err := xml.Unmarshal(data, &root); err != nil || root.XMLName.Local != "ERROR" {
+		return ""
+	}
+
+	return strings.TrimSpace(root.Message)
+}
@@ -3,20 +3,38 @@ package xml
 import (
 	"encoding/xml"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	customerrors "github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/retry/errors"
 )
 
+// eSearchResponse 定义 ESearch XML 响应中的结果及错误信息
+type eSearchResponse struct {
+	types.ESearchResult
+	Error          string   `xml:"ERROR"`
+	FieldsNotFound []string `xml:"ErrorList>FieldNotFound"`
+}
+
 // ESearchResponseParser XML 格式的 ESearch 响应解析器
 type ESearchResponseParser struct{}
 
 // ParseESearch 解析 ESearch XML 响应
 func (p *ESearchResponseParser) ParseESearch(data []byte) (*types.ESearchResult, error) {
-	var result types.ESearchResult
-	if err := xml.Unmarshal(data, &result); err != nil {
+	var response eSearchResponse
+	if err := xml.Unmarshal(data, &response); err != nil {
 		return nil, errors.Wrapf(customerrors.ErrParse, "esearch xml unmarshal failed: %v", err)
 	}
-	return &result, nil
+
+	if msg := responseError(response.Error, data); msg != "" {
+		return nil, customerrors.ClassifyEutilsError("esearch", msg)
+	}
+
+	// 检索式中的字段不存在且没有结果时, 重试结果相同
+	if response.Count == 0 && len(response.FieldsNotFound) > 0 {
+		return nil, customerrors.NewEutilsError("esearch", "field not found: "+strings.Join(response.FieldsNotFound, ", "))
+	}
+
+	return &response.ESearchResult, nil
 }
@@ -1,8 +1,10 @@
 package xml
 
 import (
+	"bytes"
 	"encoding/xml"
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/types"
+	"io"
 
 	"github.com/pkg/errors"
 
@@ -11,11 +13,88 @@ import (
 
 type ESummaryResponseParser struct{}
 
+// eSummaryError 定义 ESummary XML 响应中的错误信息
+type eSummaryError struct {
+	Error string `xml:"ERROR"`
+}
+
 // ParseESummary 解析 ESummary XML 响应
 func (h *ESummaryResponseParser) ParseESummary(data []byte) (*types.ESummaryResult, error) {
+	// 出错时以 HTTP 200 返回 <ERROR>, 如: 历史会话过期时返回 <ERROR>Unable to obtain query #1</ERROR>
+	if bytes.Contains(data, []byte("<ERROR>")) {
+		var respErr eSummaryError
+		if err := xml.Unmarshal(data, &respErr); err == nil {
+			if msg := responseError(respErr.Error, data); msg != "" {
+				return nil, customerrors.ClassifyEutilsError("esummary", msg)
+			}
+		}
+	}
+
 	var result types.ESummaryResult
 	if err := xml.Unmarshal(data, &result); err != nil {
 		return nil, errors.Wrapf(customerrors.ErrParse, "esummary xml unmarshal failed: %v", err)
 	}
+
+	if len(result.DocumentSummarySet.DocumentSummary) == 0 {
+		return nil, emptyDocumentSummarySet()
+	}
+
 	return &result, nil
 }
+
+// DecodeESummary 逐个 token 解析 ESummary XML 响应, 每解析出一个文档摘要即调用 handler
+// 内存占用只与单个文档摘要有关, 与响应大小无关; 遇到 <ERROR> 元素时立即中止, 不再读取之后的响应
+func (h *ESummaryResponseParser) DecodeESummary(r io.Reader, handler func(doc *types.DocumentSummary) error) error {
+	decoder := xml.NewDecoder(r)
+	var count int
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			if count == 0 {
+				return emptyDocumentSummarySet()
+			}
+			return nil
+		}
+		if err != nil {
+			return decodeError(err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "DocumentSummary":
+			doc := &types.DocumentSummary{}
+			if err := decoder.DecodeElement(doc, &start); err != nil {
+				return decodeError(err)
+			}
+			count++
+			if err := handler(doc); err != nil {
+				return err
+			}
+		case "ERROR":
+			var msg string
+			if err := decoder.DecodeElement(&msg, &start); err != nil {
+				return decodeError(err)
+			}
+			return customerrors.ClassifyEutilsError("esummary", msg)
+		}
+	}
+}
+
+// emptyDocumentSummarySet 响应中既没有错误信息也没有文档摘要时返回的错误
+func emptyDocumentSummarySet() error {
+	return customerrors.NewEutilsEmptyResultError("esummary", "esummary returned an empty DocumentSummarySet")
+}
+
+// decodeError 转换解析过程中的错误
+// 读取响应体失败及响应过大的错误保持原样, 以便调用方判断是否重试
+func decodeError(err error) error {
+	var retryable customerrors.RetryableError
+	if errors.As(err, &retryable) || errors.Is(err, customerrors.ErrResponseTooLarge) {
+		return err
+	}
+	return errors.Wrapf(customerrors.ErrParse, "esummary xml decode failed: %v", err)
+}
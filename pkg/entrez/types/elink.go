@@ -0,0 +1,28 @@
+package types
+
+// 支持的 elink 目标数据库
+const (
+	LinkPubMed = "pubmed"
+	LinkMedGen = "medgen"
+	LinkGene   = "gene"
+)
+
+// VariantLinks 定义通过 elink 获取的变异关联信息
+type VariantLinks struct {
+	PMIDs      []string `json:"pmids,omitempty"`       // 关联的 PubMed ID
+	MedGenCUIs []string `json:"medgen_cuis,omitempty"` // 关联的 MedGen 概念 ID, 如: C0027672
+	GeneIDs    []string `json:"gene_ids,omitempty"`    // 关联的 Gene ID
+}
+
+// ELinkResult 定义了 ELink 操作的结果结构
+// Links 以源数据库的 UID 为键, 值为目标数据库中关联的 UID
+type ELinkResult struct {
+	DbTo  string
+	Links map[string][]string
+}
+
+// MedGenSummaryResult 定义了 MedGen esummary 的结果结构
+// Concepts 以 MedGen UID 为键, 值为概念 ID (CUI)
+type MedGenSummaryResult struct {
+	Concepts map[string]string
+}
@@ -0,0 +1,98 @@
+package types
+
+import "strings"
+
+// VariantOrigin 记录变异来源的查询
+// 同一变异出现在多个查询的结果中时, 去重后只保留一条记录, 所有来源查询的信息合并到该记录中
+type VariantOrigin struct {
+	Queries         []string          // 来源查询 ID
+	Genes           []string          // 来源查询中命中该变异的输入基因名
+	Regions         []Region          // 来源查询的基因组区间
+	ResolvedSymbols map[string]string // 来源查询中经 HGNC 解析的基因名
+}
+
+// SharedVariant 出现在多个查询结果中的变异及其所有来源
+// 只保留重新计算来源相关列所需的基因名及位置, 不保留完整的文档摘要
+type SharedVariant struct {
+	Uid        string
+	Origin     *VariantOrigin
+	Genes      []string   // 变异所属的基因名
+	Assemblies []Assembly // 变异在各参考基因组上的位置, 用于按所有来源查询的区间重新检查
+}
+
+// NewSharedVariant 创建出现在多个查询结果中的变异, first 为首次命中该变异的查询
+func NewSharedVariant(first *QueryResult, doc *DocumentSummary) *SharedVariant {
+	genes := make([]string, 0, len(doc.Genes.Gene))
+	for _, gene := range doc.Genes.Gene {
+		genes = append(genes, gene.Symbol)
+	}
+
+	return &SharedVariant{
+		Uid:        doc.Uid,
+		Origin:     NewVariantOrigin(first, doc),
+		Genes:      genes,
+		Assemblies: doc.VariationSet.Variation.VariationLoc.AssemblySet,
+	}
+}
+
+// NewVariantOrigin 创建只包含一个来源查询的变异来源
+func NewVariantOrigin(result *QueryResult, doc *DocumentSummary) *VariantOrigin {
+	origin := &VariantOrigin{}
+	origin.Merge(result, doc)
+	return origin
+}
+
+// Merge 合并一个来源查询, 已存在的查询、基因名及区间不会重复添加; 查询已是来源时返回 false
+func (o *VariantOrigin) Merge(result *QueryResult, doc *DocumentSummary) bool {
+	for _, queryID := range o.Queries {
+		if queryID == result.QueryID {
+			return false
+		}
+	}
+	o.Queries = append(o.Queries, result.QueryID)
+
+	symbols := make(map[string]struct{}, len(doc.Genes.Gene))
+	for _, gene := range doc.Genes.Gene {
+		symbols[strings.ToUpper(gene.Symbol)] = struct{}{}
+	}
+
+	for _, gene := range QueryGenes(result.Query) {
+		if _, ok := symbols[strings.ToUpper(gene)]; !ok || containsFold(o.Genes, gene) {
+			continue
+		}
+		o.Genes = append(o.Genes, gene)
+	}
+
+	o.Regions = append(o.Regions, result.Regions...)
+
+	for symbol, input := range result.ResolvedSymbols {
+		if o.ResolvedSymbols == nil {
+			o.ResolvedSymbols = make(map[string]string)
+		}
+		o.ResolvedSymbols[symbol] = input
+	}
+
+	return true
+}
+
+// QueryGenes 返回查询内容中的基因名检索词
+func QueryGenes(content string) []string {
+	var genes []string
+	for _, term := range strings.Split(content, querySeparator) {
+		term = strings.TrimSpace(term)
+		if strings.HasSuffix(term, FlagGene) {
+			genes = append(genes, strings.TrimSuffix(term, FlagGene))
+		}
+	}
+	return genes
+}
+
+// containsFold 判断 values 中是否包含 value (不区分大小写)
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
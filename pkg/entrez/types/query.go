@@ -2,15 +2,26 @@ package types
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"regexp"
 	"strings"
 )
 
+// querySeparator 合并多个检索词时使用的分隔符
+const querySeparator = " OR "
+
+// invalidIDChars 匹配查询 ID 中不允许出现的字符 (如 HGVS 中的 : >)
+var invalidIDChars = regexp.MustCompile(`[^\w.-]`)
+
 // Query 定义查询信息
 type Query struct {
-	Content string // 查询内容
+	Content         string            // 查询内容
+	Regions         []Region          // 区间检索时查询内容包含的基因组区间
+	ResolvedSymbols map[string]string // 经 HGNC 解析的基因名: 大写的批准基因名 -> 原始基因名
+	IDs             []string          // 检索词均为 VariationID 时的 UID 列表, 可通过 epost 上传至历史服务器而跳过 esearch
+	SearchType      string            // 查询类型对应的检索字段标志, 如: [gene]、region
 }
 
 // NewQuery 创建新的查询
@@ -22,15 +33,49 @@ func NewQuery(content string) *Query {
 
 // GetQueryID 生成并返回查询的唯一标识符
 func (q *Query) GetQueryID() string {
-	// 取查询内容的第一个基因名作为前缀
-	prefix := extractFirstTerm(q.Content)
-
 	// 使用 MD5 生成哈希（也可用 sha256）
 	hash := md5.Sum([]byte(q.Content))
 	// 取前 6 位作为 ID
-	shortHash := hex.EncodeToString(hash[:])[:6]
+	return q.idWithHash(hex.EncodeToString(hash[:])[:6])
+}
+
+// GetCacheKey 根据缓存指纹生成缓存键, 前缀与 GetQueryID 相同
+// 指纹包含过滤条件、数据库等请求参数, 查询内容相同但参数不同时缓存键不同
+func (q *Query) GetCacheKey(fingerprint string) string {
+	hash := sha256.Sum256([]byte(fingerprint))
+	return q.idWithHash(hex.EncodeToString(hash[:])[:16])
+}
+
+// idWithHash 取查询内容的第一个检索词作为前缀, 前缀会用作缓存文件名，需替换掉文件名中的非法字符
+func (q *Query) idWithHash(hash string) string {
+	prefix := invalidIDChars.ReplaceAllString(extractFirstTerm(q.Content), "_")
+	return fmt.Sprintf("%s-%s", prefix, hash)
+}
+
+// Split 将 OR 连接的多个检索词拆分为两个子查询, 只有一个检索词时返回 nil
+// 子查询沿用原查询的区间及基因名映射, 结果合并后仍以原查询输出
+func (q *Query) Split() []*Query {
+	terms := strings.Split(q.Content, querySeparator)
+	if len(terms) < 2 {
+		return nil
+	}
+
+	mid := len(terms) / 2
+	halves := [][]string{terms[:mid], terms[mid:]}
+
+	subQueries := make([]*Query, 0, len(halves))
+	for i, half := range halves {
+		sub := NewQuery(strings.Join(half, querySeparator))
+		sub.Regions = q.Regions
+		sub.ResolvedSymbols = q.ResolvedSymbols
+		sub.SearchType = q.SearchType
+		if len(q.IDs) == len(terms) {
+			sub.IDs = q.IDs[i*mid : i*mid+len(half)]
+		}
+		subQueries = append(subQueries, sub)
+	}
 
-	return fmt.Sprintf("%s-%s", prefix, shortHash)
+	return subQueries
 }
 
 // String 实现 Stringer 接口
@@ -51,6 +96,9 @@ func extractFirstTerm(content string) string {
 	// 正则表达式匹配模式
 	patterns := []string{
 		`(\w+)\[gene\]`,    // 匹配基因
+		`(\w+)\[rs\]`,      // 匹配 rs 号
+		`([\w.]+)\[acc\]`,  // 匹配 VCV/RCV 号
+		`(\w+)\[uid\]`,     // 匹配 VariationID
 		`(\w+)\[protein\]`, // 匹配蛋白质
 		`(\w+)\[title\]`,   // 匹配标题
 		`"([^"]+)"`,        // 匹配引号内的内容
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	AssemblyGRCh37 = "GRCh37"
+	AssemblyGRCh38 = "GRCh38"
+)
+
+const (
+	RegionCheckInside  = "inside"  // 变异完全位于查询区间内
+	RegionCheckPartial = "partial" // 变异与查询区间部分重叠
+	RegionCheckOutside = "outside" // 变异位于查询区间外
+)
+
+// Region 定义基因组区间，坐标为 1-based 闭区间
+type Region struct {
+	Chr      string `json:"chr"`      // 染色体, 不带 chr 前缀, 如: 17、X
+	Start    int    `json:"start"`    // 起始位置
+	Stop     int    `json:"stop"`     // 终止位置
+	Assembly string `json:"assembly"` // 参考基因组版本: GRCh37 或 GRCh38
+}
+
+// IsValidAssembly 检查参考基因组版本是否受支持
+func IsValidAssembly(assembly string) bool {
+	return assembly == AssemblyGRCh37 || assembly == AssemblyGRCh38
+}
+
+// String 实现 Stringer 接口
+func (r Region) String() string {
+	return fmt.Sprintf("chr%s:%d-%d", r.Chr, r.Start, r.Stop)
+}
+
+// Term 构建区间对应的 clinvar 检索词, 如: (17[chr] AND 43044295:43125483[chrpos38])
+func (r Region) Term() string {
+	return fmt.Sprintf("(%s[chr] AND %d:%d[%s])", r.Chr, r.Start, r.Stop, r.positionField())
+}
+
+// positionField 返回参考基因组版本对应的位置检索字段
+func (r Region) positionField() string {
+	if r.Assembly == AssemblyGRCh37 {
+		return "chrpos37"
+	}
+	return "chrpos38"
+}
+
+// Check 检查变异在区间所属参考基因组上的坐标与区间的位置关系
+// 变异没有该参考基因组的坐标时返回空字符串
+func (r Region) Check(assemblies []Assembly) string {
+	for _, assembly := range assemblies {
+		if assembly.AssemblyName != r.Assembly {
+			continue
+		}
+
+		start, err := strconv.Atoi(assembly.Start)
+		if err != nil {
+			return ""
+		}
+		stop, err := strconv.Atoi(assembly.Stop)
+		if err != nil {
+			stop = start
+		}
+
+		switch {
+		case !strings.EqualFold(assembly.Chr, r.Chr) || stop < r.Start || start > r.Stop:
+			return RegionCheckOutside
+		case start >= r.Start && stop <= r.Stop:
+			return RegionCheckInside
+		default:
+			return RegionCheckPartial
+		}
+	}
+
+	return ""
+}
+
+// CheckRegions 检查变异与多个查询区间的位置关系，取最接近区间内的结果
+func CheckRegions(regions []Region, assemblies []Assembly) string {
+	var result string
+	for _, region := range regions {
+		switch region.Check(assemblies) {
+		case RegionCheckInside:
+			return RegionCheckInside
+		case RegionCheckPartial:
+			result = RegionCheckPartial
+		case RegionCheckOutside:
+			if result == "" {
+				result = RegionCheckOutside
+			}
+		}
+	}
+
+	return result
+}
@@ -12,8 +12,10 @@ type ESearchResult struct {
 
 // EPostResult 定义了 EPost 操作的结果结构
 type EPostResult struct {
-	QueryKey string `xml:"QueryKey"`
-	WebEnv   string `xml:"WebEnv"`
+	QueryKey      string   `xml:"QueryKey"`
+	WebEnv        string   `xml:"WebEnv"`
+	InvalidIdList []string `xml:"InvalidIdList>Id"` // 数据库中不存在的 UID
+	Error         string   `xml:"ERROR"`
 }
 
 // ESummaryResult 定义了 ESummary 操作的结果结构
@@ -42,6 +44,10 @@ type DocumentSummary struct {
 	Genes                        GeneList        `xml:"genes"`
 	MolecularConsequenceList     ConsequenceList `xml:"molecular_consequence_list"`
 	ProteinChange                string          `xml:"protein_change"`
+	VCV                          *VCVRecord      `xml:"-"`          // 启用 efetch 时附加的完整 VCV 记录
+	Links                        *VariantLinks   `xml:"-"`          // 启用 elink 时附加的关联记录
+	Error                        string          `xml:"error"`      // UID 无法获取摘要时 esummary 返回的错误信息
+	Origin                       *VariantOrigin  `xml:"-" json:"-"` // 去重后合并的来源查询, 只在写入结果时使用, 不写入缓存
 }
 
 // VariationSet 定义了变异集合
@@ -120,11 +126,17 @@ type ConsequenceList struct {
 
 // TraitInfo 定义了特征信息的结构
 type TraitInfo struct {
-	TraitXrefs struct {
-		TraitXref []struct {
-			DBSource string `xml:"db_source"`
-			DbId     string `xml:"db_id"`
-		} `xml:"trait_xref"`
-	} `xml:"trait_xrefs"`
-	Name string `xml:"trait_name"`
+	TraitXrefs TraitXrefs `xml:"trait_xrefs"`
+	Name       string     `xml:"trait_name"`
+}
+
+// TraitXrefs 定义了特征引用列表
+type TraitXrefs struct {
+	TraitXref []TraitXref `xml:"trait_xref"`
+}
+
+// TraitXref 定义了特征引用
+type TraitXref struct {
+	DBSource string `xml:"db_source"`
+	DbId     string `xml:"db_id"`
 }
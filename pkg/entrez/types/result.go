@@ -17,21 +17,28 @@ type QueryStatus string
 
 // QueryResult 单个查询的统计信息
 type QueryResult struct {
-	QueryID             string          `json:"query_id"`                 // 查询ID
-	Query               string          `json:"query"`                    // 查询内容
-	TotalRecords        int             `json:"total_records"`            // esearch 返回的总记录数
-	ProcessedCount      int             `json:"processed_count"`          // 成功处理的记录数
-	Status              QueryStatus     `json:"status"`                   // 查询状态（完全成功/部分成功/失败）
-	FailedBatches       []BatchInfo     `json:"failed_batches,omitempty"` // 失败的批次信息（如果有）
-	TotalBatches        int             `json:"total_batches"`            // 总批次数
-	Error               error           `json:"error,omitempty"`          // 错误信息（如果有）
-	CreatedAt           time.Time       `json:"created_at"`               // 开始时间
-	EndTime             time.Time       `json:"end_time"`                 // 结束时间
-	Duration            string          `json:"duration"`                 // 耗时
-	Progress            string          `json:"progress"`                 // 进度
-	Result              *ESummaryResult `json:"result"`                   // 查询结果
-	LastQueryHasFilters bool            `json:"last_query_has_filters"`   // 上一次查询是否有过滤条件
-	mu                  sync.Mutex      `json:"-"`
+	QueryID             string            `json:"query_id"`                   // 查询ID
+	Query               string            `json:"query"`                      // 查询内容
+	TotalRecords        int               `json:"total_records"`              // esearch 返回的总记录数
+	ProcessedCount      int               `json:"processed_count"`            // 成功处理的记录数
+	Status              QueryStatus       `json:"status"`                     // 查询状态（完全成功/部分成功/失败）
+	FailedBatches       []BatchInfo       `json:"failed_batches,omitempty"`   // 失败的批次信息（如果有）
+	TotalBatches        int               `json:"total_batches"`              // 总批次数
+	Error               error             `json:"error,omitempty"`            // 错误信息（如果有）
+	CreatedAt           time.Time         `json:"created_at"`                 // 开始时间
+	EndTime             time.Time         `json:"end_time"`                   // 结束时间
+	Duration            string            `json:"duration"`                   // 耗时
+	Progress            string            `json:"progress"`                   // 进度
+	Result              *ESummaryResult   `json:"result"`                     // 查询结果
+	LastQueryHasFilters bool              `json:"last_query_has_filters"`     // 上一次查询是否有过滤条件
+	Regions             []Region          `json:"regions,omitempty"`          // 区间检索时查询的基因组区间
+	ResolvedSymbols     map[string]string `json:"resolved_symbols,omitempty"` // 经 HGNC 解析的基因名
+	SplitQueries        int               `json:"split_queries,omitempty"`    // 结果过多时拆分出的子查询数量
+	MissingUIDs         []string          `json:"missing_uids,omitempty"`     // 核对后仍无法获取的 UID
+	Fingerprint         string            `json:"fingerprint,omitempty"`      // 缓存指纹, 记录生成结果时的全部请求参数
+	CacheVersion        int               `json:"cache_version,omitempty"`    // 缓存格式版本
+	BatchKeys           []string          `json:"batch_keys,omitempty"`       // 保存文档摘要的批次缓存键, 拆分查询时为各子查询的缓存键
+	mu                  sync.Mutex        `json:"-"`
 }
 
 // NewQueryResult 创建新的查询结果
@@ -139,6 +146,63 @@ func (qr *QueryResult) GetQueryStatusContent() string {
 	}
 }
 
+// SetMissingUIDs 设置核对后仍无法获取的 UID
+func (qr *QueryResult) SetMissingUIDs(uids []string) {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	qr.MissingUIDs = uids
+}
+
+// SetFingerprint 记录缓存指纹
+func (qr *QueryResult) SetFingerprint(fingerprint string) {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	qr.Fingerprint = fingerprint
+}
+
+// SetCacheVersion 记录缓存格式版本
+func (qr *QueryResult) SetCacheVersion(version int) {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	qr.CacheVersion = version
+}
+
+// WithResolvedSymbols 返回使用新的基因名映射的浅拷贝, 不修改原结果
+// 缓存命中的结果可能同时被多个查询使用, 需要修改时先复制
+func (qr *QueryResult) WithResolvedSymbols(symbols map[string]string) *QueryResult {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+
+	resolved := make(map[string]string, len(symbols))
+	for symbol, input := range symbols {
+		resolved[symbol] = input
+	}
+
+	return &QueryResult{
+		QueryID:             qr.QueryID,
+		Query:               qr.Query,
+		TotalRecords:        qr.TotalRecords,
+		ProcessedCount:      qr.ProcessedCount,
+		Status:              qr.Status,
+		FailedBatches:       qr.FailedBatches,
+		TotalBatches:        qr.TotalBatches,
+		Error:               qr.Error,
+		CreatedAt:           qr.CreatedAt,
+		EndTime:             qr.EndTime,
+		Duration:            qr.Duration,
+		Progress:            qr.Progress,
+		Result:              qr.Result,
+		LastQueryHasFilters: qr.LastQueryHasFilters,
+		Regions:             qr.Regions,
+		ResolvedSymbols:     resolved,
+		SplitQueries:        qr.SplitQueries,
+		MissingUIDs:         qr.MissingUIDs,
+		Fingerprint:         qr.Fingerprint,
+		CacheVersion:        qr.CacheVersion,
+		BatchKeys:           qr.BatchKeys,
+	}
+}
+
 // RemoveFailedBatch 从失败批次列表中移除指定批次
 func (qr *QueryResult) RemoveFailedBatch(start int) {
 	qr.mu.Lock()
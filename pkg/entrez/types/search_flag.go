@@ -0,0 +1,13 @@
+package types
+
+// ClinVar 检索字段标志
+// 详细：https://www.ncbi.nlm.nih.gov/clinvar/docs/help/
+const (
+	FlagGene        = "[gene]"         // 基因名, 如: BRCA1[gene]
+	FlagRsID        = "[rs]"           // dbSNP rs 号, 如: rs80357906[rs]
+	FlagHGVS        = "[variant name]" // HGVS 表达式, 如: "NM_007294.4:c.5266dup"[variant name]
+	FlagVariationID = "[uid]"          // ClinVar VariationID, 如: 17661[uid]
+	FlagAccession   = "[acc]"          // VCV/RCV 号, 如: VCV000017661[acc]
+	FlagRegion      = "region"         // 非 ClinVar 字段: 基因组区间, 如: chr17:43044295-43125483
+	FlagMixed       = "mixed"          // 非 ClinVar 字段: 按行自动识别标识符类型
+)
@@ -3,6 +3,7 @@ package types
 import (
 	"github.com/iEchoxu/clinvarDL/pkg/entrez/pkg/logcdl"
 
+	"strings"
 	"sync"
 	"sync/atomic"
 )
@@ -19,6 +20,9 @@ type Stats struct {
 	FailedQueries    *sync.Map // 失败的查询集合
 	failedCount      int       // 失败的查询数量
 	PartialFailures  *sync.Map // 部分失败的查询及其失败记录
+	MissingUIDs      *sync.Map // 核对后仍无法获取的 UID
+	DuplicateRecords int       // 去重时移除的不同查询之间的重复记录数
+	SharedVariants   int       // 出现在多个查询结果中的变异数
 }
 
 // NewStats 创建新的统计对象
@@ -26,6 +30,7 @@ func NewStats() *Stats {
 	s := &Stats{
 		FailedQueries:   &sync.Map{},
 		PartialFailures: &sync.Map{},
+		MissingUIDs:     &sync.Map{},
 	}
 	return s
 }
@@ -35,6 +40,17 @@ func (s *Stats) AddPartialFailures(queryID string, failedBatches Batch) {
 	s.PartialFailures.Store(queryID, failedBatches)
 }
 
+// AddMissingUIDs 记录核对后仍无法获取的 UID
+func (s *Stats) AddMissingUIDs(queryID string, uids []string) {
+	s.MissingUIDs.Store(queryID, uids)
+}
+
+// SetDuplicates 设置去重统计
+func (s *Stats) SetDuplicates(duplicateRecords, sharedVariants int) {
+	s.DuplicateRecords = duplicateRecords
+	s.SharedVariants = sharedVariants
+}
+
 // SetTotalQueries 设置总查询数
 func (s *Stats) SetTotalQueries(count int) {
 	s.TotalQueries = count
@@ -62,6 +78,10 @@ func (s *Stats) PrintSummary() {
 	logcdl.Info("- completed queries: %d", s.CompletedQueries)
 	logcdl.Info("- total records: %d", s.TotalRecords)
 	logcdl.Info("- records processed: %d", s.ProcessedRecords)
+	if s.DuplicateRecords > 0 {
+		logcdl.Info("- duplicate records merged: %d (%d variants found by multiple queries)", s.DuplicateRecords, s.SharedVariants)
+		logcdl.Info("- unique records: %d", int(s.ProcessedRecords)-s.DuplicateRecords)
+	}
 
 	// 打印部分成功的查询详情
 	var partialCount int
@@ -79,6 +99,18 @@ func (s *Stats) PrintSummary() {
 		return true
 	})
 
+	// 打印核对后仍缺失的 UID
+	var missingCount int
+	s.MissingUIDs.Range(func(key, value interface{}) bool {
+		if missingCount == 0 {
+			logcdl.Warn("queries with unrecoverable uids:")
+		}
+		uids := value.([]string)
+		logcdl.Warn("  - query '%v': %d uids: %s", key, len(uids), strings.Join(uids, ","))
+		missingCount++
+		return true
+	})
+
 	// 打印失败的查询详情
 	var count int
 	s.FailedQueries.Range(func(key, value interface{}) bool {
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VCVRecord 定义从 efetch rettype=vcv 返回的完整 VCV 记录中提取的提交者级别信息
+// esummary 只提供汇总后的分类, 单个 SCV 提交、提交者、判定标准及文献只能从完整记录中获取
+type VCVRecord struct {
+	VariationID         string       `json:"variation_id"`          // VariationID, 与 DocumentSummary.Uid 对应
+	Accession           string       `json:"accession"`             // VCV 号
+	Version             string       `json:"version"`               // VCV 版本
+	NumberOfSubmitters  int          `json:"number_of_submitters"`  // 提交者数量
+	NumberOfSubmissions int          `json:"number_of_submissions"` // 提交数量
+	HGVS                []string     `json:"hgvs,omitempty"`        // 所有转录本上的 HGVS 表达式
+	Submissions         []Submission `json:"submissions,omitempty"` // SCV 提交列表
+}
+
+// Submission 定义单个 SCV 提交
+type Submission struct {
+	Accession         string   `json:"accession"`                    // SCV 号
+	Submitter         string   `json:"submitter"`                    // 提交者名称
+	Classification    string   `json:"classification"`               // 提交者给出的分类
+	ReviewStatus      string   `json:"review_status"`                // 审核状态
+	DateLastEvaluated string   `json:"date_last_evaluated"`          // 最后评估日期
+	AssertionCriteria []string `json:"assertion_criteria,omitempty"` // 判定标准, 如: ACMG Guidelines, 2015
+	Citations         []string `json:"citations,omitempty"`          // 引用的 PubMed ID
+}
+
+// Submitters 返回所有提交者名称 (去重并保持顺序)
+func (r *VCVRecord) Submitters() []string {
+	var submitters []string
+	seen := make(map[string]struct{})
+	for _, s := range r.Submissions {
+		if _, ok := seen[s.Submitter]; ok || s.Submitter == "" {
+			continue
+		}
+		seen[s.Submitter] = struct{}{}
+		submitters = append(submitters, s.Submitter)
+	}
+	return submitters
+}
+
+// Citations 返回所有提交引用的 PubMed ID (去重并按数值排序)
+func (r *VCVRecord) Citations() []string {
+	seen := make(map[string]struct{})
+	var citations []string
+	for _, s := range r.Submissions {
+		for _, pmid := range s.Citations {
+			if _, ok := seen[pmid]; ok {
+				continue
+			}
+			seen[pmid] = struct{}{}
+			citations = append(citations, pmid)
+		}
+	}
+	sort.Slice(citations, func(i, j int) bool {
+		if len(citations[i]) != len(citations[j]) {
+			return len(citations[i]) < len(citations[j])
+		}
+		return citations[i] < citations[j]
+	})
+	return citations
+}
+
+// ConflictingSubmitters 在提交者给出的分类不一致时按分类列出提交者
+// 格式: Pathogenic: A|B; Uncertain significance: C, 分类一致时返回空字符串
+func (r *VCVRecord) ConflictingSubmitters() string {
+	var classifications []string
+	submitters := make(map[string][]string)
+	for _, s := range r.Submissions {
+		if s.Classification == "" {
+			continue
+		}
+		if _, ok := submitters[s.Classification]; !ok {
+			classifications = append(classifications, s.Classification)
+		}
+		submitters[s.Classification] = append(submitters[s.Classification], s.Submitter)
+	}
+
+	if len(classifications) < 2 {
+		return ""
+	}
+
+	groups := make([]string, 0, len(classifications))
+	for _, classification := range classifications {
+		groups = append(groups, fmt.Sprintf("%s: %s", classification, strings.Join(submitters[classification], "|")))
+	}
+
+	return strings.Join(groups, "; ")
+}
+
+// EFetchResult 定义了 EFetch 操作的结果结构
+type EFetchResult struct {
+	Records []*VCVRecord
+}